@@ -2,6 +2,7 @@ package jpush
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 )
 
@@ -35,12 +36,17 @@ type AdminSuccessResponse struct {
 // AdminApp admin app point
 // POST /v1/admin/app
 func (j *JPush) AdminApp(req **AdminAppRequest) (*AdminAppResponse, error) {
+	return j.AdminAppContext(context.Background(), req)
+}
+
+// AdminAppContext like AdminApp, with ctx for cancellation and deadline
+func (j *JPush) AdminAppContext(ctx context.Context, req **AdminAppRequest) (*AdminAppResponse, error) {
 	url := j.GetURL("admin") + "app"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,9 +61,14 @@ func (j *JPush) AdminApp(req **AdminAppRequest) (*AdminAppResponse, error) {
 // AdminAppDelete delete app
 // POST /v1/app/{appkey}/delete
 func (j *JPush) AdminAppDelete(appkey string) (*AdminSuccessResponse, error) {
+	return j.AdminAppDeleteContext(context.Background(), appkey)
+}
+
+// AdminAppDeleteContext like AdminAppDelete, with ctx for cancellation and deadline
+func (j *JPush) AdminAppDeleteContext(ctx context.Context, appkey string) (*AdminSuccessResponse, error) {
 	url := j.GetURL("admin") + "app/" + appkey + "/delete"
 
-	resp, err := j.request("POST", url, nil, nil)
+	resp, err := j.request(ctx, "POST", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -72,12 +83,17 @@ func (j *JPush) AdminAppDelete(appkey string) (*AdminSuccessResponse, error) {
 // AdminAppCert admin certificate
 // POST /v1/app/{appKey}/certificate
 func (j *JPush) AdminAppCert(appkey string, req *AdminCertificateRequest) (*AdminSuccessResponse, error) {
+	return j.AdminAppCertContext(context.Background(), appkey, req)
+}
+
+// AdminAppCertContext like AdminAppCert, with ctx for cancellation and deadline
+func (j *JPush) AdminAppCertContext(ctx context.Context, appkey string, req *AdminCertificateRequest) (*AdminSuccessResponse, error) {
 	url := j.GetURL("admin") + "app/" + appkey + "/certificate"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
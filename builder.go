@@ -0,0 +1,633 @@
+package jpush
+
+import "errors"
+
+// PushBuilder fluent builder of PushRequest
+//
+//	req, err := jpush.NewPush().Android().IOS().
+//		ToTags("vip").ToAliases("alice").
+//		Alert("hello").AndroidTitle("title").
+//		TTL(3600).ApnsProduction(true).
+//		Build()
+type PushBuilder struct {
+	cid             string
+	allPlatform     bool
+	platforms       []string
+	allAudience     bool
+	audience        Audience
+	notification    *PushNotification
+	message         *PushMessage
+	sms             *SmsMessage
+	notification3rd *Notification3rd
+	inapp           *InAppMessage
+	liveActivity    *LiveActivity
+	options         *PushOptions
+	callback        *Callback
+}
+
+// NewPush new push request builder
+func NewPush() *PushBuilder {
+	return &PushBuilder{}
+}
+
+// Cid set the cid to avoid duplicate push on retry
+func (b *PushBuilder) Cid(cid string) *PushBuilder {
+	b.cid = cid
+	return b
+}
+
+// AllPlatforms push to all platforms
+func (b *PushBuilder) AllPlatforms() *PushBuilder {
+	b.allPlatform = true
+	return b
+}
+
+// Platforms add platforms to push
+func (b *PushBuilder) Platforms(platforms ...string) *PushBuilder {
+	for _, platform := range platforms {
+		if !b.hasPlatform(platform) {
+			b.platforms = append(b.platforms, platform)
+		}
+	}
+	return b
+}
+
+// Android add android platform
+func (b *PushBuilder) Android() *PushBuilder {
+	return b.Platforms(PlatformAndroid)
+}
+
+// IOS add ios platform
+func (b *PushBuilder) IOS() *PushBuilder {
+	return b.Platforms(PlatformIOS)
+}
+
+// HMOS add harmonyos next platform
+func (b *PushBuilder) HMOS() *PushBuilder {
+	return b.Platforms(PlatformHMOS)
+}
+
+// QuickApp add quickapp platform
+func (b *PushBuilder) QuickApp() *PushBuilder {
+	return b.Platforms(PlatformQuickApp)
+}
+
+// WinPhone add winphone platform
+//
+// Deprecated: winphone push is no longer supported by JPush
+func (b *PushBuilder) WinPhone() *PushBuilder {
+	return b.Platforms(PlatformWinPhone)
+}
+
+// ToAll push to all devices
+func (b *PushBuilder) ToAll() *PushBuilder {
+	b.allAudience = true
+	return b
+}
+
+// ToTags push to devices with any of tags
+func (b *PushBuilder) ToTags(tags ...string) *PushBuilder {
+	b.audience.Tag = append(b.audience.Tag, tags...)
+	return b
+}
+
+// ToTagsAnd push to devices with all of tags
+func (b *PushBuilder) ToTagsAnd(tags ...string) *PushBuilder {
+	b.audience.TagAnd = append(b.audience.TagAnd, tags...)
+	return b
+}
+
+// ToTagsNot push to devices without any of tags
+func (b *PushBuilder) ToTagsNot(tags ...string) *PushBuilder {
+	b.audience.TagNot = append(b.audience.TagNot, tags...)
+	return b
+}
+
+// ToAliases push to devices with any of aliases
+func (b *PushBuilder) ToAliases(aliases ...string) *PushBuilder {
+	b.audience.Alias = append(b.audience.Alias, aliases...)
+	return b
+}
+
+// ToRegistrationIDs push to devices by registration ids
+func (b *PushBuilder) ToRegistrationIDs(ids ...string) *PushBuilder {
+	b.audience.RegistrationID = append(b.audience.RegistrationID, ids...)
+	return b
+}
+
+// ToSegments push to user segments
+func (b *PushBuilder) ToSegments(segments ...string) *PushBuilder {
+	b.audience.Segment = append(b.audience.Segment, segments...)
+	return b
+}
+
+// ToABTest push to ab test groups
+func (b *PushBuilder) ToABTest(abtests ...string) *PushBuilder {
+	b.audience.ABTest = append(b.audience.ABTest, abtests...)
+	return b
+}
+
+// ToFile push to registration ids or aliases of the uploaded file,
+// send the request with PushFile
+func (b *PushBuilder) ToFile(fileID string) *PushBuilder {
+	b.audience.File = &AudienceFile{FileID: fileID}
+	return b
+}
+
+// ToLiveActivity push to the live activity id, used with LiveActivity
+func (b *PushBuilder) ToLiveActivity(id string) *PushBuilder {
+	b.audience.LiveActivityID = id
+	return b
+}
+
+// getNotification get or create notification
+func (b *PushBuilder) getNotification() *PushNotification {
+	if b.notification == nil {
+		b.notification = &PushNotification{}
+	}
+	return b.notification
+}
+
+// getAndroid get or create android notification
+func (b *PushBuilder) getAndroid() *NotificationAndroid {
+	n := b.getNotification()
+	if n.Android == nil {
+		n.Android = &NotificationAndroid{}
+	}
+	return n.Android
+}
+
+// getIOS get or create ios notification
+func (b *PushBuilder) getIOS() *NotificationIOS {
+	n := b.getNotification()
+	if n.IOS == nil {
+		n.IOS = &NotificationIOS{}
+	}
+	return n.IOS
+}
+
+// getOptions get or create options
+func (b *PushBuilder) getOptions() *PushOptions {
+	if b.options == nil {
+		b.options = &PushOptions{}
+	}
+	return b.options
+}
+
+// Alert set the notification alert of all platforms
+func (b *PushBuilder) Alert(alert string) *PushBuilder {
+	b.getNotification().Alert = alert
+	return b
+}
+
+// AndroidNotification set the android notification
+func (b *PushBuilder) AndroidNotification(android *NotificationAndroid) *PushBuilder {
+	b.getNotification().Android = android
+	return b
+}
+
+// AndroidAlert set the android notification alert
+func (b *PushBuilder) AndroidAlert(alert string) *PushBuilder {
+	b.getAndroid().Alert = alert
+	return b
+}
+
+// AndroidTitle set the android notification title
+func (b *PushBuilder) AndroidTitle(title string) *PushBuilder {
+	b.getAndroid().Title = title
+	return b
+}
+
+// AndroidExtras set the android notification extras
+func (b *PushBuilder) AndroidExtras(extras map[string]interface{}) *PushBuilder {
+	b.getAndroid().Extras = extras
+	return b
+}
+
+// IOSNotification set the ios notification
+func (b *PushBuilder) IOSNotification(ios *NotificationIOS) *PushBuilder {
+	b.getNotification().IOS = ios
+	return b
+}
+
+// IOSAlert set the ios notification alert, string or *IOSAlert
+func (b *PushBuilder) IOSAlert(alert interface{}) *PushBuilder {
+	b.getIOS().Alert = alert
+	return b
+}
+
+// IOSSound set the ios notification sound
+func (b *PushBuilder) IOSSound(sound string) *PushBuilder {
+	b.getIOS().Sound = sound
+	return b
+}
+
+// IOSCriticalSound set the ios notification critical alert sound
+func (b *PushBuilder) IOSCriticalSound(name string, volume float64) *PushBuilder {
+	b.getIOS().Sound = &IOSSound{Critical: 1, Name: name, Volume: volume}
+	return b
+}
+
+// IOSBadge set the ios notification badge, such as "1" or "+1"
+func (b *PushBuilder) IOSBadge(badge IOSBadge) *PushBuilder {
+	b.getIOS().Badge = badge
+	return b
+}
+
+// IOSThreadID set the ios notification thread id
+func (b *PushBuilder) IOSThreadID(threadID string) *PushBuilder {
+	b.getIOS().ThreadID = threadID
+	return b
+}
+
+// IOSInterruptionLevel set the ios notification interruption level
+func (b *PushBuilder) IOSInterruptionLevel(level InterruptionLevel) *PushBuilder {
+	b.getIOS().InterruptionLevel = level
+	return b
+}
+
+// IOSExtras set the ios notification extras
+func (b *PushBuilder) IOSExtras(extras map[string]interface{}) *PushBuilder {
+	b.getIOS().Extras = extras
+	return b
+}
+
+// HMOSNotification set the harmonyos next notification
+func (b *PushBuilder) HMOSNotification(hmos *NotificationHMOS) *PushBuilder {
+	b.getNotification().HMOS = hmos
+	return b
+}
+
+// QuickAppNotification set the quickapp notification
+func (b *PushBuilder) QuickAppNotification(quickapp *NotificationQuickApp) *PushBuilder {
+	b.getNotification().QuickApp = quickapp
+	return b
+}
+
+// WinPhoneNotification set the winphone notification
+//
+// Deprecated: winphone push is no longer supported by JPush
+func (b *PushBuilder) WinPhoneNotification(winphone *NotificationWinPhone) *PushBuilder {
+	b.getNotification().WinPhone = winphone
+	return b
+}
+
+// Message set the custom message
+func (b *PushBuilder) Message(message *PushMessage) *PushBuilder {
+	b.message = message
+	return b
+}
+
+// MessageContent set the custom message content
+func (b *PushBuilder) MessageContent(content string) *PushBuilder {
+	if b.message == nil {
+		b.message = &PushMessage{}
+	}
+	b.message.MsgContent = content
+	return b
+}
+
+// SmsMessage set the sms message
+func (b *PushBuilder) SmsMessage(sms *SmsMessage) *PushBuilder {
+	b.sms = sms
+	return b
+}
+
+// Notification3rd set the vendor notification of the custom message
+func (b *PushBuilder) Notification3rd(n *Notification3rd) *PushBuilder {
+	b.notification3rd = n
+	return b
+}
+
+// InAppMessage enable the in-app message of the notification
+func (b *PushBuilder) InAppMessage(enable bool) *PushBuilder {
+	b.inapp = &InAppMessage{InAppMessage: enable}
+	return b
+}
+
+// LiveActivity set the ios live activity payload
+func (b *PushBuilder) LiveActivity(ios *LiveActivityIOS) *PushBuilder {
+	b.liveActivity = &LiveActivity{IOS: ios}
+	return b
+}
+
+// Options set the push options
+func (b *PushBuilder) Options(options *PushOptions) *PushBuilder {
+	b.options = options
+	return b
+}
+
+// SendNo set the sendno option
+func (b *PushBuilder) SendNo(sendNo int) *PushBuilder {
+	b.getOptions().SendNo = sendNo
+	return b
+}
+
+// TTL set the offline message keep seconds
+func (b *PushBuilder) TTL(seconds int) *PushBuilder {
+	b.getOptions().TimeToLive = seconds
+	return b
+}
+
+// OverrideMsgID set the msg id to override
+func (b *PushBuilder) OverrideMsgID(msgID int64) *PushBuilder {
+	b.getOptions().OverrideMsgID = msgID
+	return b
+}
+
+// ApnsProduction set the apns environment
+func (b *PushBuilder) ApnsProduction(production bool) *PushBuilder {
+	b.getOptions().ApnsProduction = production
+	return b
+}
+
+// ApnsCollapseID set the apns collapse id
+func (b *PushBuilder) ApnsCollapseID(id string) *PushBuilder {
+	b.getOptions().ApnsCollapseID = id
+	return b
+}
+
+// BigPushDuration set the minutes to spread the push
+func (b *PushBuilder) BigPushDuration(minutes int) *PushBuilder {
+	b.getOptions().BigPushDuration = minutes
+	return b
+}
+
+// ThirdPartyChannel set the vendor channel options
+func (b *PushBuilder) ThirdPartyChannel(channel *ThirdPartyChannel) *PushBuilder {
+	b.getOptions().ThirdPartyChannel = channel
+	return b
+}
+
+// Callback set the delivery callback
+func (b *PushBuilder) Callback(url string, types CallbackType, params map[string]interface{}) *PushBuilder {
+	b.callback = &Callback{URL: url, Params: params, Type: types}
+	return b
+}
+
+// hasPlatform check if platform is targeted
+func (b *PushBuilder) hasPlatform(platform string) bool {
+	if b.allPlatform {
+		return true
+	}
+	for _, p := range b.platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTarget check if any specific audience target set
+func (b *PushBuilder) hasTarget() bool {
+	a := b.audience
+	return len(a.Tag) > 0 || len(a.TagAnd) > 0 || len(a.TagNot) > 0 || len(a.Alias) > 0 ||
+		len(a.RegistrationID) > 0 || len(a.Segment) > 0 || len(a.ABTest) > 0 || a.File != nil || a.LiveActivityID != ""
+}
+
+// check check the invalid combinations
+func (b *PushBuilder) check() error {
+	if !b.allPlatform && len(b.platforms) == 0 {
+		return errors.New("jpush: no platform")
+	}
+	if b.allAudience && b.hasTarget() {
+		return errors.New("jpush: audience all mixed with specific targets")
+	}
+	if !b.allAudience && !b.hasTarget() {
+		return errors.New("jpush: no audience")
+	}
+	if b.liveActivity != nil {
+		if b.notification != nil || b.message != nil {
+			return errors.New("jpush: live activity mixed with notification or message")
+		}
+	} else if b.notification == nil && b.message == nil {
+		return errors.New("jpush: no notification and no message")
+	}
+	if n := b.notification; n != nil {
+		if n.Android != nil && !b.hasPlatform(PlatformAndroid) {
+			return errors.New("jpush: android notification set but android is not targeted")
+		}
+		if n.IOS != nil && !b.hasPlatform(PlatformIOS) {
+			return errors.New("jpush: ios notification set but ios is not targeted")
+		}
+		if n.HMOS != nil && !b.hasPlatform(PlatformHMOS) {
+			return errors.New("jpush: hmos notification set but hmos is not targeted")
+		}
+		if n.QuickApp != nil && !b.hasPlatform(PlatformQuickApp) {
+			return errors.New("jpush: quickapp notification set but quickapp is not targeted")
+		}
+		if n.WinPhone != nil && !b.hasPlatform(PlatformWinPhone) {
+			return errors.New("jpush: winphone notification set but winphone is not targeted")
+		}
+	}
+	return nil
+}
+
+// Build build the push request, return error for invalid combinations
+// or ValidationError when the request violates the limits. The request
+// has its own copies of the structs set to the builder, the maps such as
+// Extras and the interface values are still shared
+func (b *PushBuilder) Build() (*PushRequest, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	platform := &Platform{}
+	if b.allPlatform {
+		platform.SetAll(true)
+	} else {
+		platform.Platforms = append([]string(nil), b.platforms...)
+	}
+	audience := &PushAudience{}
+	if b.allAudience {
+		audience.SetAll(true)
+	} else {
+		audience.Aud = b.audience.clone()
+	}
+	// copy what the builder and the caller still hold, so reuse of them
+	// not change the built request
+	notification := b.notification.clone()
+	if n := notification; n != nil && n.Alert != "" {
+		// the platform alert override the common alert, empty means not display
+		if n.Android != nil && n.Android.Alert == "" {
+			n.Android.Alert = n.Alert
+		}
+		if n.IOS != nil && n.IOS.Alert == nil {
+			n.IOS.Alert = n.Alert
+		}
+		if n.HMOS != nil && n.HMOS.Alert == "" {
+			n.HMOS.Alert = n.Alert
+		}
+		if n.QuickApp != nil && n.QuickApp.Alert == "" {
+			n.QuickApp.Alert = n.Alert
+		}
+		if n.WinPhone != nil && n.WinPhone.Alert == "" {
+			n.WinPhone.Alert = n.Alert
+		}
+	}
+	req := &PushRequest{
+		Cid:             b.cid,
+		Platform:        platform,
+		Audience:        audience,
+		Notification:    notification,
+		Notification3rd: b.notification3rd.clone(),
+		Message:         b.message.clone(),
+		InAppMessage:    b.inapp.clone(),
+		SmsMessage:      b.sms.clone(),
+		LiveActivity:    b.liveActivity.clone(),
+		Options:         b.options.clone(),
+		Callback:        b.callback.clone(),
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// clone copy the audience with its own slices
+func (a Audience) clone() *Audience {
+	c := a
+	c.Tag = append([]string(nil), a.Tag...)
+	c.TagAnd = append([]string(nil), a.TagAnd...)
+	c.TagNot = append([]string(nil), a.TagNot...)
+	c.Alias = append([]string(nil), a.Alias...)
+	c.RegistrationID = append([]string(nil), a.RegistrationID...)
+	c.Segment = append([]string(nil), a.Segment...)
+	c.ABTest = append([]string(nil), a.ABTest...)
+	if a.File != nil {
+		f := *a.File
+		c.File = &f
+	}
+	return &c
+}
+
+// clone copy the notification and the platform notifications
+func (n *PushNotification) clone() *PushNotification {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	if n.Android != nil {
+		android := *n.Android
+		c.Android = &android
+	}
+	if n.IOS != nil {
+		ios := *n.IOS
+		c.IOS = &ios
+	}
+	if n.HMOS != nil {
+		hmos := *n.HMOS
+		c.HMOS = &hmos
+	}
+	if n.QuickApp != nil {
+		quickapp := *n.QuickApp
+		c.QuickApp = &quickapp
+	}
+	if n.WinPhone != nil {
+		winphone := *n.WinPhone
+		c.WinPhone = &winphone
+	}
+	return &c
+}
+
+// clone copy the message
+func (m *PushMessage) clone() *PushMessage {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	return &c
+}
+
+// clone copy the vendor notification
+func (n *Notification3rd) clone() *Notification3rd {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
+}
+
+// clone copy the in-app message
+func (m *InAppMessage) clone() *InAppMessage {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	return &c
+}
+
+// clone copy the sms message
+func (m *SmsMessage) clone() *SmsMessage {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	return &c
+}
+
+// clone copy the live activity, its ios payload and alert
+func (l *LiveActivity) clone() *LiveActivity {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	if l.IOS != nil {
+		ios := *l.IOS
+		if l.IOS.Alert != nil {
+			alert := *l.IOS.Alert
+			ios.Alert = &alert
+		}
+		c.IOS = &ios
+	}
+	return &c
+}
+
+// clone copy the callback
+func (cb *Callback) clone() *Callback {
+	if cb == nil {
+		return nil
+	}
+	c := *cb
+	return &c
+}
+
+// clone copy the options and the vendor channel options
+func (o *PushOptions) clone() *PushOptions {
+	if o == nil {
+		return nil
+	}
+	c := *o
+	if ch := o.ThirdPartyChannel; ch != nil {
+		channel := *ch
+		if ch.Xiaomi != nil {
+			xiaomi := *ch.Xiaomi
+			channel.Xiaomi = &xiaomi
+		}
+		if ch.Huawei != nil {
+			huawei := *ch.Huawei
+			channel.Huawei = &huawei
+		}
+		if ch.Honor != nil {
+			honor := *ch.Honor
+			channel.Honor = &honor
+		}
+		if ch.Oppo != nil {
+			oppo := *ch.Oppo
+			channel.Oppo = &oppo
+		}
+		if ch.Vivo != nil {
+			vivo := *ch.Vivo
+			channel.Vivo = &vivo
+		}
+		if ch.Meizu != nil {
+			meizu := *ch.Meizu
+			channel.Meizu = &meizu
+		}
+		if ch.Fcm != nil {
+			fcm := *ch.Fcm
+			channel.Fcm = &fcm
+		}
+		c.ThirdPartyChannel = &channel
+	}
+	return &c
+}
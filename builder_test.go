@@ -0,0 +1,93 @@
+package jpush_test
+
+import (
+	"testing"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+func TestBuilderReuse(t *testing.T) {
+	b := jpush.NewPush().Android().IOS().ToTags("a").Alert("hello").SendNo(1)
+	first, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.ToTags("b").Alert("bye").SendNo(2).AndroidTitle("title")
+	if _, err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if tags := first.Audience.Aud.Tag; len(tags) != 1 || tags[0] != "a" {
+		t.Errorf("first tags changed to %v", tags)
+	}
+	if first.Notification.Alert != "hello" {
+		t.Errorf("first alert changed to %q", first.Notification.Alert)
+	}
+	if first.Options.SendNo != 1 {
+		t.Errorf("first sendno changed to %d", first.Options.SendNo)
+	}
+	if first.Notification.Android != nil {
+		t.Errorf("first android notification set to %+v", first.Notification.Android)
+	}
+}
+
+func TestBuilderNotificationNotMutated(t *testing.T) {
+	android := &jpush.NotificationAndroid{Title: "title"}
+	ios := &jpush.NotificationIOS{Sound: "default"}
+	req, err := jpush.NewPush().Android().IOS().ToAll().Alert("hello").
+		AndroidNotification(android).IOSNotification(ios).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if android.Alert != "" || ios.Alert != nil {
+		t.Errorf("caller notification changed: android %q, ios %v", android.Alert, ios.Alert)
+	}
+	if req.Notification.Android.Alert != "hello" || req.Notification.IOS.Alert != "hello" {
+		t.Errorf("common alert not filled: android %q, ios %v", req.Notification.Android.Alert, req.Notification.IOS.Alert)
+	}
+
+	req2, err := jpush.NewPush().Android().ToAll().Alert("bye").AndroidNotification(android).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Notification.Android.Alert != "hello" || req2.Notification.Android.Alert != "bye" {
+		t.Errorf("got alerts %q and %q, want hello and bye", req.Notification.Android.Alert, req2.Notification.Android.Alert)
+	}
+}
+
+func TestBuilderSharedStructs(t *testing.T) {
+	channel := &jpush.ThirdPartyChannel{Xiaomi: &jpush.ThirdPartyXiaomi{Distribution: jpush.DistributionSecondaryPush}}
+	notification3rd := &jpush.Notification3rd{Content: "offline"}
+	sms := &jpush.SmsMessage{DelayTime: 60}
+	b := jpush.NewPush().Android().ToAll().MessageContent("hello").
+		Notification3rd(notification3rd).SmsMessage(sms).ThirdPartyChannel(channel).
+		Callback("https://example.com/callback", jpush.CallbackReceived, nil)
+	req, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel.Xiaomi.Distribution = jpush.DistributionJPush
+	notification3rd.Content = "changed"
+	sms.DelayTime = 0
+	b.Callback("https://example.com/other", jpush.CallbackClicked, nil)
+	if req.Options.ThirdPartyChannel.Xiaomi.Distribution != jpush.DistributionSecondaryPush {
+		t.Errorf("got xiaomi distribution %q", req.Options.ThirdPartyChannel.Xiaomi.Distribution)
+	}
+	if req.Notification3rd.Content != "offline" || req.SmsMessage.DelayTime != 60 {
+		t.Errorf("got notification_3rd %q and sms delay %d", req.Notification3rd.Content, req.SmsMessage.DelayTime)
+	}
+	if req.Callback.URL != "https://example.com/callback" {
+		t.Errorf("got callback %q", req.Callback.URL)
+	}
+
+	alert := &jpush.LiveActivityAlert{Title: "title"}
+	update := &jpush.LiveActivityIOS{Event: jpush.LiveActivityUpdate, ContentState: map[string]interface{}{"eta": 10}, Alert: alert}
+	req, err = jpush.NewPush().IOS().ToLiveActivity("activity").LiveActivity(update).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	update.Event = jpush.LiveActivityEnd
+	alert.Title = "changed"
+	if req.LiveActivity.IOS.Event != jpush.LiveActivityUpdate || req.LiveActivity.IOS.Alert.Title != "title" {
+		t.Errorf("got live activity %+v", req.LiveActivity.IOS)
+	}
+}
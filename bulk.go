@@ -0,0 +1,220 @@
+package jpush
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// BulkPusher push a template request to a large list of registration ids
+// or aliases, the list is split into chunks of the audience limit and sent
+// with bounded concurrency
+type BulkPusher struct {
+	client      *JPush
+	ChunkSize   int          // 每次推送的目标数，默认 MaxRegistrationIDs
+	Concurrency int          // 并发推送数，默认 4
+	Retry       *RetryPolicy // 失败分片的重试策略，nil 不重试，分片推送不再使用客户端的重试策略
+	UseCid      bool         // 为每个分片获取 cid，避免重试造成重复推送
+}
+
+// BulkChunkResult push result of one chunk
+type BulkChunkResult struct {
+	Index    int      // 分片序号
+	Targets  []string // 分片的推送目标
+	MsgID    string   // 推送成功的 msg_id
+	Attempts int      // 尝试次数
+	Err      error    // 推送失败的错误
+}
+
+// BulkResult aggregated push result of all chunks
+type BulkResult struct {
+	Chunks []BulkChunkResult
+}
+
+// MsgIDs get msg ids of all succeeded chunks
+func (r *BulkResult) MsgIDs() []string {
+	var ids []string
+	for _, chunk := range r.Chunks {
+		if chunk.Err == nil {
+			ids = append(ids, chunk.MsgID)
+		}
+	}
+	return ids
+}
+
+// Failed get all failed chunks
+func (r *BulkResult) Failed() []BulkChunkResult {
+	var failed []BulkChunkResult
+	for _, chunk := range r.Chunks {
+		if chunk.Err != nil {
+			failed = append(failed, chunk)
+		}
+	}
+	return failed
+}
+
+// Err get the error of the first failed chunk
+func (r *BulkResult) Err() error {
+	for _, chunk := range r.Chunks {
+		if chunk.Err != nil {
+			return chunk.Err
+		}
+	}
+	return nil
+}
+
+// NewBulkPusher new bulk pusher of client
+func NewBulkPusher(client *JPush) *BulkPusher {
+	return &BulkPusher{
+		client:      client,
+		ChunkSize:   MaxRegistrationIDs,
+		Concurrency: 4,
+		Retry:       DefaultRetryPolicy(),
+		UseCid:      true,
+	}
+}
+
+// PushRegistrationIDs push template to registration ids, the audience of
+// template is replaced by each chunk of ids, so it must be nil, all or
+// registration ids, tag and other filters are rejected
+func (b *BulkPusher) PushRegistrationIDs(ctx context.Context, template *PushRequest, ids []string) (*BulkResult, error) {
+	return b.push(ctx, template, ids, func(aud *Audience, chunk []string) {
+		aud.RegistrationID = chunk
+	})
+}
+
+// PushAliases push template to aliases, the audience of template is
+// replaced by each chunk of aliases, so it must be nil, all or aliases,
+// tag and other filters are rejected
+func (b *BulkPusher) PushAliases(ctx context.Context, template *PushRequest, aliases []string) (*BulkResult, error) {
+	return b.push(ctx, template, aliases, func(aud *Audience, chunk []string) {
+		aud.Alias = chunk
+	})
+}
+
+// push split targets into chunks and push them, the audience and cid of
+// template are replaced for each chunk
+func (b *BulkPusher) push(ctx context.Context, template *PushRequest, targets []string, setTarget func(*Audience, []string)) (*BulkResult, error) {
+	if template == nil {
+		return nil, errors.New("jpush: nil push template")
+	}
+	if a := template.Audience; a != nil && a.Aud != nil {
+		aud := a.Aud
+		if len(aud.Tag) > 0 || len(aud.TagAnd) > 0 || len(aud.TagNot) > 0 || len(aud.Segment) > 0 ||
+			len(aud.ABTest) > 0 || aud.File != nil || aud.LiveActivityID != "" {
+			// the chunk audience replace the template audience, the filters would be dropped
+			return nil, errors.New("jpush: bulk push template audience has filters other than the targets")
+		}
+	}
+	size := b.ChunkSize
+	if size <= 0 || size > MaxRegistrationIDs {
+		size = MaxRegistrationIDs
+	}
+	result := &BulkResult{}
+	for i := 0; i < len(targets); i += size {
+		end := i + size
+		if end > len(targets) {
+			end = len(targets)
+		}
+		result.Chunks = append(result.Chunks, BulkChunkResult{Index: len(result.Chunks), Targets: targets[i:end]})
+	}
+	if len(result.Chunks) == 0 {
+		return result, nil
+	}
+	var cids []string
+	if b.UseCid {
+		var err error
+		cids, err = b.cids(ctx, len(result.Chunks))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range result.Chunks {
+		chunk := &result.Chunks[i]
+		req := *template
+		req.Cid = ""
+		if cids != nil {
+			req.Cid = cids[i]
+		}
+		aud := &Audience{}
+		setTarget(aud, chunk.Targets)
+		req.Audience = &PushAudience{Aud: aud}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			chunk.Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			b.pushChunk(ctx, &req, chunk)
+		}()
+	}
+	wg.Wait()
+	return result, nil
+}
+
+// cids get n cids for chunks
+func (b *BulkPusher) cids(ctx context.Context, n int) ([]string, error) {
+	var cids []string
+	for len(cids) < n {
+		count := n - len(cids)
+		if count > 1000 {
+			count = 1000
+		}
+		resp, err := b.client.PushGetCidContext(ctx, count, "push")
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Cids) == 0 {
+			return nil, errors.New("jpush: no cid returned")
+		}
+		cids = append(cids, resp.Cids...)
+	}
+	return cids[:n], nil
+}
+
+// pushChunk push one chunk with retry
+func (b *BulkPusher) pushChunk(ctx context.Context, req *PushRequest, chunk *BulkChunkResult) {
+	attempts := 1
+	if b.Retry != nil && b.Retry.MaxAttempts > 1 {
+		attempts = b.Retry.MaxAttempts
+	}
+	for {
+		chunk.Attempts++
+		if err := b.client.waitQuota(ctx); err != nil {
+			chunk.Err = err
+			return
+		}
+		// the chunk is retried here, the client retry would multiply the attempts
+		resp, err := b.client.PushContext(withoutRetry(ctx), req)
+		if err == nil {
+			chunk.MsgID = resp.MsgID
+			chunk.Err = nil
+			return
+		}
+		chunk.Err = err
+		if chunk.Attempts >= attempts || !IsRetryable(err) || (req.Cid == "" && !IsRateLimited(err)) {
+			// without cid, only the rejected request is safe to retry
+			return
+		}
+		wait := b.Retry.backoff(chunk.Attempts)
+		if rateWait := retryAfter(err); rateWait > wait {
+			wait = rateWait
+		}
+		if !sleep(ctx, wait) {
+			chunk.Err = ctx.Err()
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package jpush_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+// countTransport count the requests of path
+type countTransport struct {
+	path  string
+	count int32
+}
+
+func (c *countTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Path == c.path {
+		atomic.AddInt32(&c.count, 1)
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestBulkPushNoNestedRetry(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.InjectError(jpushtest.EndpointPush, jpushtest.Fault{Status: 500, Code: jpush.CodeInternalError, Message: "internal error"})
+
+	transport := &countTransport{path: "/v3/push"}
+	client := srv.JPushClient(jpush.WithTransport(transport), jpush.WithRetryPolicy(&jpush.RetryPolicy{MaxAttempts: 3}))
+	bulk := jpush.NewBulkPusher(client)
+	bulk.Retry = &jpush.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}
+
+	template, err := jpush.NewPush().Android().ToRegistrationIDs("placeholder").Alert("hello").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret, err := bulk.PushRegistrationIDs(context.Background(), template, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.Err() == nil {
+		t.Fatal("want chunk error")
+	}
+	if n := atomic.LoadInt32(&transport.count); n != 3 {
+		t.Errorf("got %d push calls, want 3", n)
+	}
+}
+
+func TestBulkPushRejectFilterAudience(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+
+	template, err := jpush.NewPush().Android().ToTags("vip").Alert("hello").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jpush.NewBulkPusher(srv.JPushClient()).PushAliases(context.Background(), template, []string{"a"}); err == nil {
+		t.Error("want error for template with tag audience")
+	}
+}
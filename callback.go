@@ -0,0 +1,34 @@
+package jpush
+
+import "net/url"
+
+// CallbackType define callback event types, combine the types with |
+type CallbackType int
+
+// callback event types
+const (
+	CallbackReceived CallbackType = 1 // 送达回执
+	CallbackClicked  CallbackType = 2 // 点击回执
+	CallbackSent     CallbackType = 8 // 推送成功回执
+)
+
+// Callback define the delivery callback of push, JPush POST the events to
+// URL with Params
+type Callback struct {
+	URL    string                 `json:"url,omitempty"` // 为空时使用控制台配置的回调地址
+	Params map[string]interface{} `json:"params,omitempty"`
+	Type   CallbackType           `json:"type,omitempty"`
+}
+
+// checkCallback check the callback url and type
+func checkCallback(field string, c *Callback, add func(field, format string, args ...interface{})) {
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(field+".url", "%q is not a http url", c.URL)
+		}
+	}
+	if c.Type&^(CallbackReceived|CallbackClicked|CallbackSent) != 0 {
+		add(field+".type", "unknown type %d", c.Type)
+	}
+}
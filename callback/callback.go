@@ -0,0 +1,160 @@
+// Package callback receives the delivery callbacks JPush POST to the
+// callback url of a push.
+//
+// The Handler parses the events, checks them and delivers them to a func
+// or a channel:
+//
+//	events := make(chan callback.Event, 100)
+//	h := callback.NewChanHandler(events)
+//	h.Params = map[string]string{"token": "secret"}
+//	http.Handle("/jpush/callback", h)
+//
+// The delivery is at least once: when an event of the request fails, the
+// handler responds an error and JPush retries the whole request, so the
+// events delivered before arrive again. Deduplicate them with Event.Key.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// MaxBodySize max bytes of a callback request body
+const MaxBodySize = 1 << 20
+
+// Event callback event of one device
+type Event struct {
+	Type           jpush.CallbackType     `json:"type"` // CallbackReceived、CallbackClicked 或 CallbackSent
+	AppKey         string                 `json:"appkey"`
+	MsgID          string                 `json:"msg_id"`
+	RegistrationID string                 `json:"registration_id"`
+	Alias          string                 `json:"alias,omitempty"`
+	Platform       string                 `json:"platform"`
+	Channel        string                 `json:"channel,omitempty"` // 送达的厂商通道
+	Timestamp      int64                  `json:"time"`              // 事件时间，unix 毫秒
+	Params         map[string]interface{} `json:"params,omitempty"`  // 推送时设置的 callback.params
+}
+
+// Key get the stable key of event to deduplicate the retried events
+func (e *Event) Key() string {
+	return e.MsgID + ":" + e.RegistrationID + ":" + strconv.Itoa(int(e.Type))
+}
+
+// Time get the event time
+func (e *Event) Time() time.Time {
+	return time.Unix(0, e.Timestamp*int64(time.Millisecond))
+}
+
+// Handler http handler of the callback url
+type Handler struct {
+	Params map[string]string                        // 推送时设置的 callback.params，值不一致的回调被拒绝
+	Verify func(r *http.Request, body []byte) error // 校验签名等，返回错误时回调被拒绝
+
+	fn func(ctx context.Context, event Event) error
+	ch chan<- Event
+}
+
+// NewHandler new handler deliver the events to fn, the request fails and
+// is retried by JPush when fn returns error, the events of the request
+// delivered before are delivered again, see Event.Key
+func NewHandler(fn func(ctx context.Context, event Event) error) *Handler {
+	return &Handler{fn: fn}
+}
+
+// NewChanHandler new handler deliver the events to ch, the request blocks
+// until all events are received from ch, the request fails when it is
+// canceled and the events sent before are sent again on retry
+func NewChanHandler(ch chan<- Event) *Handler {
+	return &Handler{ch: ch}
+}
+
+// Parse parse the callback body, a json array of events or a single event
+func Parse(body []byte) ([]Event, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errors.New("callback: empty body")
+	}
+	if body[0] == '[' {
+		var events []Event
+		if err := json.Unmarshal(body, &events); err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return []Event{event}, nil
+}
+
+// check check the params of event
+func (h *Handler) check(event *Event) error {
+	for key, value := range h.Params {
+		got, ok := event.Params[key]
+		if !ok || fmt.Sprint(got) != value {
+			return fmt.Errorf("callback: param %s mismatch", key)
+		}
+	}
+	return nil
+}
+
+// deliver deliver event to the func or channel
+func (h *Handler) deliver(ctx context.Context, event Event) error {
+	if h.fn != nil {
+		return h.fn(ctx, event)
+	}
+	if h.ch != nil {
+		select {
+		case h.ch <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only support POST method", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if h.Verify != nil {
+		if err := h.Verify(r, body); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	events, err := Parse(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range events {
+		if err := h.check(&events[i]); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	for _, event := range events {
+		if err := h.deliver(r.Context(), event); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
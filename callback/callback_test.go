@@ -0,0 +1,60 @@
+package callback_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/callback"
+)
+
+const body = `[
+	{"type":1,"msg_id":"100","registration_id":"a","params":{"token":"secret"}},
+	{"type":2,"msg_id":"100","registration_id":"a","params":{"token":"secret"}}
+]`
+
+func post(h http.Handler, body string) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(body)))
+	return w.Code
+}
+
+func TestHandlerRetryDedupe(t *testing.T) {
+	seen := make(map[string]int)
+	fail := true
+	h := callback.NewHandler(func(ctx context.Context, event callback.Event) error {
+		if event.Type == jpush.CallbackClicked && fail {
+			fail = false
+			return errors.New("temporary failure")
+		}
+		seen[event.Key()]++
+		return nil
+	})
+	h.Params = map[string]string{"token": "secret"}
+
+	if code := post(h, body); code != http.StatusInternalServerError {
+		t.Fatalf("first post: got %d, want 500", code)
+	}
+	if code := post(h, body); code != http.StatusOK {
+		t.Fatalf("retried post: got %d, want 200", code)
+	}
+	// the received event is delivered twice with the same key
+	if len(seen) != 2 || seen["100:a:1"] != 2 || seen["100:a:2"] != 1 {
+		t.Errorf("got %v", seen)
+	}
+}
+
+func TestHandlerRejectParams(t *testing.T) {
+	h := callback.NewHandler(func(ctx context.Context, event callback.Event) error {
+		t.Error("event delivered with wrong params")
+		return nil
+	})
+	h.Params = map[string]string{"token": "other"}
+	if code := post(h, body); code != http.StatusForbidden {
+		t.Errorf("got %d, want 403", code)
+	}
+}
@@ -0,0 +1,179 @@
+package jpush
+
+// Distribution define how a vendor channel delivers the notification
+type Distribution string
+
+// distribution of vendor channel
+const (
+	DistributionJPush         Distribution = "jpush"          // 只走极光通道
+	DistributionOSPush        Distribution = "ospush"         // 只走厂商通道
+	DistributionSecondaryPush Distribution = "secondary_push" // 极光通道优先，不在线时走厂商通道
+	DistributionFirstOSPush   Distribution = "first_ospush"   // 厂商通道优先，失败时走极光通道
+)
+
+// valid check if the distribution is known
+func (d Distribution) valid() bool {
+	switch d {
+	case "", DistributionJPush, DistributionOSPush, DistributionSecondaryPush, DistributionFirstOSPush:
+		return true
+	}
+	return false
+}
+
+// DistributionFcm define how the notification is delivered to the devices
+// with fcm
+type DistributionFcm string
+
+// distribution of devices with fcm
+const (
+	DistributionFcmJPush DistributionFcm = "jpush" // 走极光通道
+	DistributionFcmFcm   DistributionFcm = "fcm"   // 走 fcm 通道
+	DistributionFcmPns   DistributionFcm = "pns"   // 走厂商通道
+)
+
+// valid check if the fcm distribution is known
+func (d DistributionFcm) valid() bool {
+	switch d {
+	case "", DistributionFcmJPush, DistributionFcmFcm, DistributionFcmPns:
+		return true
+	}
+	return false
+}
+
+// distribution a distribution value to check
+type distribution interface {
+	valid() bool
+}
+
+// vendor importance and urgency levels
+const (
+	ImportanceHigh   = "HIGH"
+	ImportanceNormal = "NORMAL"
+	ImportanceLow    = "LOW"
+)
+
+// ThirdPartyXiaomi define xiaomi channel options
+type ThirdPartyXiaomi struct {
+	Distribution    Distribution    `json:"distribution,omitempty"`
+	DistributionFcm DistributionFcm `json:"distribution_fcm,omitempty"`
+	ChannelID       string          `json:"channel_id,omitempty"`
+	SkipQuota       bool            `json:"skip_quota,omitempty"`
+	LargeIcon       string          `json:"large_icon,omitempty"`
+	SmallIconURI    string          `json:"small_icon_uri,omitempty"`
+	SmallIconColor  string          `json:"small_icon_color,omitempty"`
+	Style           int             `json:"style,omitempty"`
+	BigText         string          `json:"big_text,omitempty"`
+	BigPicPath      string          `json:"big_pic_path,omitempty"`
+}
+
+// ThirdPartyHuawei define huawei channel options
+type ThirdPartyHuawei struct {
+	Distribution       Distribution    `json:"distribution,omitempty"`
+	DistributionFcm    DistributionFcm `json:"distribution_fcm,omitempty"`
+	ChannelID          string          `json:"channel_id,omitempty"`
+	SkipQuota          bool            `json:"skip_quota,omitempty"`
+	Importance         string          `json:"importance,omitempty"` // ImportanceHigh、ImportanceNormal 或 ImportanceLow
+	Urgency            string          `json:"urgency,omitempty"`    // ImportanceHigh 或 ImportanceNormal
+	Category           string          `json:"category,omitempty"`
+	LargeIcon          string          `json:"large_icon,omitempty"`
+	SmallIconURI       string          `json:"small_icon_uri,omitempty"`
+	Style              int             `json:"style,omitempty"`
+	BigText            string          `json:"big_text,omitempty"`
+	OnlyUseVendorStyle bool            `json:"only_use_vendor_style,omitempty"`
+	TargetUserType     int             `json:"target_user_type,omitempty"`
+	ReceiptID          string          `json:"receipt_id,omitempty"`
+}
+
+// ThirdPartyHonor define honor channel options
+type ThirdPartyHonor struct {
+	Distribution    Distribution    `json:"distribution,omitempty"`
+	DistributionFcm DistributionFcm `json:"distribution_fcm,omitempty"`
+	SkipQuota       bool            `json:"skip_quota,omitempty"`
+	Importance      string          `json:"importance,omitempty"` // ImportanceNormal 或 ImportanceLow
+	LargeIcon       string          `json:"large_icon,omitempty"`
+	SmallIconURI    string          `json:"small_icon_uri,omitempty"`
+	Style           int             `json:"style,omitempty"`
+	BigText         string          `json:"big_text,omitempty"`
+}
+
+// ThirdPartyOppo define oppo channel options
+type ThirdPartyOppo struct {
+	Distribution    Distribution    `json:"distribution,omitempty"`
+	DistributionFcm DistributionFcm `json:"distribution_fcm,omitempty"`
+	ChannelID       string          `json:"channel_id,omitempty"`
+	SkipQuota       bool            `json:"skip_quota,omitempty"`
+	Category        string          `json:"category,omitempty"`
+	NotifyLevel     int             `json:"notify_level,omitempty"`
+	LargeIcon       string          `json:"large_icon,omitempty"`
+	SmallIconURI    string          `json:"small_icon_uri,omitempty"`
+	Style           int             `json:"style,omitempty"`
+	BigText         string          `json:"big_text,omitempty"`
+	BigPicPath      string          `json:"big_pic_path,omitempty"`
+}
+
+// ThirdPartyVivo define vivo channel options
+type ThirdPartyVivo struct {
+	Distribution    Distribution    `json:"distribution,omitempty"`
+	DistributionFcm DistributionFcm `json:"distribution_fcm,omitempty"`
+	SkipQuota       bool            `json:"skip_quota,omitempty"`
+	Classification  int             `json:"classification,omitempty"` // 0 运营消息，1 系统消息
+	PushMode        int             `json:"push_mode,omitempty"`      // 0 正式推送，1 测试推送
+	Category        string          `json:"category,omitempty"`
+}
+
+// ThirdPartyMeizu define meizu channel options
+type ThirdPartyMeizu struct {
+	Distribution    Distribution    `json:"distribution,omitempty"`
+	DistributionFcm DistributionFcm `json:"distribution_fcm,omitempty"`
+	SkipQuota       bool            `json:"skip_quota,omitempty"`
+}
+
+// ThirdPartyFcm define fcm channel options
+type ThirdPartyFcm struct {
+	Distribution Distribution `json:"distribution,omitempty"`
+	SkipQuota    bool         `json:"skip_quota,omitempty"`
+}
+
+// ThirdPartyChannel define vendor channel options of android devices
+type ThirdPartyChannel struct {
+	Xiaomi *ThirdPartyXiaomi `json:"xiaomi,omitempty"`
+	Huawei *ThirdPartyHuawei `json:"huawei,omitempty"`
+	Honor  *ThirdPartyHonor  `json:"honor,omitempty"`
+	Oppo   *ThirdPartyOppo   `json:"oppo,omitempty"`
+	Vivo   *ThirdPartyVivo   `json:"vivo,omitempty"`
+	Meizu  *ThirdPartyMeizu  `json:"meizu,omitempty"`
+	Fcm    *ThirdPartyFcm    `json:"fcm,omitempty"`
+}
+
+// distributions get the distributions of all vendors keyed by field name
+func (c *ThirdPartyChannel) distributions() map[string]distribution {
+	ret := make(map[string]distribution)
+	if c.Xiaomi != nil {
+		ret["xiaomi.distribution"] = c.Xiaomi.Distribution
+		ret["xiaomi.distribution_fcm"] = c.Xiaomi.DistributionFcm
+	}
+	if c.Huawei != nil {
+		ret["huawei.distribution"] = c.Huawei.Distribution
+		ret["huawei.distribution_fcm"] = c.Huawei.DistributionFcm
+	}
+	if c.Honor != nil {
+		ret["honor.distribution"] = c.Honor.Distribution
+		ret["honor.distribution_fcm"] = c.Honor.DistributionFcm
+	}
+	if c.Oppo != nil {
+		ret["oppo.distribution"] = c.Oppo.Distribution
+		ret["oppo.distribution_fcm"] = c.Oppo.DistributionFcm
+	}
+	if c.Vivo != nil {
+		ret["vivo.distribution"] = c.Vivo.Distribution
+		ret["vivo.distribution_fcm"] = c.Vivo.DistributionFcm
+	}
+	if c.Meizu != nil {
+		ret["meizu.distribution"] = c.Meizu.Distribution
+		ret["meizu.distribution_fcm"] = c.Meizu.DistributionFcm
+	}
+	if c.Fcm != nil {
+		ret["fcm.distribution"] = c.Fcm.Distribution
+	}
+	return ret
+}
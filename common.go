@@ -33,6 +33,9 @@ func init() {
 
 // GetURL get the api url address
 func (j *JPush) GetURL(key string) string {
+	if url, ok := j.urls[key]; ok {
+		return url
+	}
 	if urls, ok := ZONES[j.Zone]; ok {
 		if url, ok := urls[key]; ok {
 			return url
@@ -1,36 +1,53 @@
 package jpush
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
-	"strconv"
 )
 
-//JPush jpush core struct
+// JPush jpush core struct
 type JPush struct {
 	appKey       string
 	masterSecret string
 	auth         string
 	Zone         string
 	client       *http.Client
-	Quota        int // 当前 AppKey 一个时间窗口内可调用次数
-	Remaining    int // 当前时间窗口剩余的可用次数
-	Reset        int // 距离时间窗口重置剩余的秒数
+	urls         map[string]string
+	userAgent    string
+	retry        *RetryPolicy
+	limiter      rateLimiter
+	// Deprecated: Quota, Remaining and Reset are still written by every
+	// response, reading them while another goroutine sends a request is a
+	// data race. Use RateLimit instead, which is safe for concurrent use.
+	Quota     int // 当前 AppKey 一个时间窗口内可调用次数
+	Remaining int // 当前时间窗口剩余的可用次数
+	Reset     int // 距离时间窗口重置剩余的秒数
 }
 
 // NewJPush new jpush object
-func NewJPush(key, secret string) *JPush {
+func NewJPush(key, secret string, opts ...Option) *JPush {
 	jpush := &JPush{appKey: key, masterSecret: secret}
 	jpush.auth = fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(key+":"+secret)))
-	jpush.Zone = "default"
-	jpush.client = &http.Client{}
+	jpush.setup(opts)
 	return jpush
 }
 
+// setup set default values and apply options
+func (j *JPush) setup(opts []Option) {
+	j.Zone = "default"
+	j.client = &http.Client{}
+	j.userAgent = "jpush-api-golang"
+	for _, opt := range opts {
+		opt(j)
+	}
+}
+
 // SetAuthorization set Authorization
 func (j *JPush) SetAuthorization(key, secret string) {
 	j.appKey = key
@@ -40,14 +57,62 @@ func (j *JPush) SetAuthorization(key, secret string) {
 
 // SetZone set jpush zone
 func (j *JPush) SetZone(zone string) {
-	if _, ok := ZONES[j.Zone]; ok {
+	if _, ok := ZONES[zone]; ok {
 		j.Zone = zone
 	}
 }
 
-// request request api func
-func (j *JPush) request(method, url string, body io.Reader, params map[string]string) ([]byte, error) {
-	httpReq, err := http.NewRequest(method, url, body)
+// SetRetryPolicy set the retry policy, nil disable retry
+func (j *JPush) SetRetryPolicy(policy *RetryPolicy) {
+	j.retry = policy
+}
+
+// request request api func, the request is bound to ctx and retried
+// according to the retry policy
+func (j *JPush) request(ctx context.Context, method, url string, body io.Reader, params map[string]string) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	attempts := 1
+	if j.retry != nil && !isRetryDisabled(ctx) && (isIdempotent(method) || isRetryContext(ctx)) {
+		attempts = j.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		if err := j.limiter.acquire(ctx); err != nil {
+			return nil, err
+		}
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		buf, err := j.do(ctx, method, url, body, "application/json;charset:utf-8", params)
+		if err == nil {
+			return buf, nil
+		}
+		if attempt >= attempts || ctx.Err() != nil || !IsRetryable(err) {
+			return nil, err
+		}
+		wait := j.retry.backoff(attempt)
+		if rateWait := retryAfter(err); rateWait > wait {
+			if j.retry.MaxWait > 0 && rateWait > j.retry.MaxWait {
+				return nil, err
+			}
+			wait = rateWait
+		}
+		if !sleep(ctx, wait) {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// do send one http request, return body or *APIError when status is not 200
+func (j *JPush) do(ctx context.Context, method, url string, body io.Reader, contentType string, params map[string]string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -58,54 +123,67 @@ func (j *JPush) request(method, url string, body io.Reader, params map[string]st
 	httpReq.URL.RawQuery = q.Encode()
 
 	httpReq.Header.Set("Authorization", j.auth)
-	httpReq.Header.Set("User-Agent", "jpush-api-golang")
-	httpReq.Header.Set("Content-Type", "application/json;charset:utf-8")
+	httpReq.Header.Set("User-Agent", j.userAgent)
+	httpReq.Header.Set("Content-Type", contentType)
 	httpReq.Header.Set("connection", "keep-alive")
 	resp, err := j.client.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	limit, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Quota"))
-	if err == nil {
-		j.Quota = limit
-	}
-	limit, err = strconv.Atoi(resp.Header.Get("X-Rate-Limit-Remaining"))
-	if err == nil {
-		j.Remaining = limit
-	}
-	limit, err = strconv.Atoi(resp.Header.Get("X-Rate-Limit-Reset"))
-	if err == nil {
-		j.Reset = limit
-	}
+	j.updateRateLimit(resp.Header)
 	buf, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode != 200 {
-		var jErr ErrorResponse
-		err = json.Unmarshal(buf, &jErr)
-		if err != nil {
-			return nil, err
-		}
-		return nil, jErr.Error
+		return nil, newAPIError(resp, buf)
 	}
 	return buf, nil
 }
 
-//GroupPush grouppush core struct
+// upload stream r as the multipart file field to url, the body is not
+// buffered so the request is never retried
+func (j *JPush) upload(ctx context.Context, method, url, field, filename string, r io.Reader, fields map[string]string) ([]byte, error) {
+	if err := j.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		for key, value := range fields {
+			if err := writer.WriteField(key, value); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		part, err := writer.CreateFormFile(field, filename)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	buf, err := j.do(ctx, method, url, pr, writer.FormDataContentType(), nil)
+	// unblock the writer if the request failed before reading the body
+	pr.CloseWithError(io.ErrClosedPipe)
+	return buf, err
+}
+
+// GroupPush grouppush core struct
 type GroupPush struct {
 	JPush
 }
 
 // NewGroupPush new grouppush object
-func NewGroupPush(key, secret string) *GroupPush {
+func NewGroupPush(key, secret string, opts ...Option) *GroupPush {
 	jpush := &GroupPush{}
 	jpush.appKey = key
 	jpush.masterSecret = secret
 	jpush.auth = fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte("group-"+key+":"+secret)))
-	jpush.Zone = "default"
-	jpush.client = &http.Client{}
+	jpush.setup(opts)
 	return jpush
 }
 
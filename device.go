@@ -2,6 +2,7 @@ package jpush
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strings"
 )
@@ -59,9 +60,14 @@ type DeviceStatusResponse struct {
 
 // DeviceGetRegistrationID get device info
 func (j *JPush) DeviceGetRegistrationID(registrationID string) (*DeviceRegistrationIDResponse, error) {
+	return j.DeviceGetRegistrationIDContext(context.Background(), registrationID)
+}
+
+// DeviceGetRegistrationIDContext like DeviceGetRegistrationID, with ctx for cancellation and deadline
+func (j *JPush) DeviceGetRegistrationIDContext(ctx context.Context, registrationID string) (*DeviceRegistrationIDResponse, error) {
 	url := j.GetURL("device") + registrationID
 
-	resp, err := j.request("GET", url, nil, nil)
+	resp, err := j.request(ctx, "GET", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -75,13 +81,18 @@ func (j *JPush) DeviceGetRegistrationID(registrationID string) (*DeviceRegistrat
 
 // DevicePostRegistrationID modify device info
 func (j *JPush) DevicePostRegistrationID(registrationID string, req *DeviceRegistrationIDRequest) (*DefaultResponse, error) {
+	return j.DevicePostRegistrationIDContext(context.Background(), registrationID, req)
+}
+
+// DevicePostRegistrationIDContext like DevicePostRegistrationID, with ctx for cancellation and deadline
+func (j *JPush) DevicePostRegistrationIDContext(ctx context.Context, registrationID string, req *DeviceRegistrationIDRequest) (*DefaultResponse, error) {
 	url := j.GetURL("device") + registrationID
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -95,13 +106,18 @@ func (j *JPush) DevicePostRegistrationID(registrationID string, req *DeviceRegis
 
 // DeviceGetAlias get device named alias
 func (j *JPush) DeviceGetAlias(alias string, platforms []string) (*DeviceAliasResponse, error) {
+	return j.DeviceGetAliasContext(context.Background(), alias, platforms)
+}
+
+// DeviceGetAliasContext like DeviceGetAlias, with ctx for cancellation and deadline
+func (j *JPush) DeviceGetAliasContext(ctx context.Context, alias string, platforms []string) (*DeviceAliasResponse, error) {
 	url := j.GetURL("alias") + alias
 	params := make(map[string]string)
 	if len(platforms) > 0 {
 		params["platform"] = strings.Join(platforms, ",")
 	}
 
-	resp, err := j.request("GET", url, nil, params)
+	resp, err := j.request(ctx, "GET", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -115,13 +131,18 @@ func (j *JPush) DeviceGetAlias(alias string, platforms []string) (*DeviceAliasRe
 
 // DeviceDeleteAlias delete alias
 func (j *JPush) DeviceDeleteAlias(alias string, platforms []string) (*DefaultResponse, error) {
+	return j.DeviceDeleteAliasContext(context.Background(), alias, platforms)
+}
+
+// DeviceDeleteAliasContext like DeviceDeleteAlias, with ctx for cancellation and deadline
+func (j *JPush) DeviceDeleteAliasContext(ctx context.Context, alias string, platforms []string) (*DefaultResponse, error) {
 	url := j.GetURL("alias") + alias
 	params := make(map[string]string)
 	if len(platforms) > 0 {
 		params["platform"] = strings.Join(platforms, ",")
 	}
 
-	resp, err := j.request("DELETE", url, nil, params)
+	resp, err := j.request(ctx, "DELETE", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -135,9 +156,14 @@ func (j *JPush) DeviceDeleteAlias(alias string, platforms []string) (*DefaultRes
 
 // DeviceGetTags get all tag list
 func (j *JPush) DeviceGetTags() (*DeviceTagsListResponse, error) {
+	return j.DeviceGetTagsContext(context.Background())
+}
+
+// DeviceGetTagsContext like DeviceGetTags, with ctx for cancellation and deadline
+func (j *JPush) DeviceGetTagsContext(ctx context.Context) (*DeviceTagsListResponse, error) {
 	url := j.GetURL("tag")
 
-	resp, err := j.request("GET", url, nil, nil)
+	resp, err := j.request(ctx, "GET", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -151,9 +177,14 @@ func (j *JPush) DeviceGetTags() (*DeviceTagsListResponse, error) {
 
 // DeviceGetTagsRegistrationID get device tag
 func (j *JPush) DeviceGetTagsRegistrationID(tag string, registrationID string) (*DeviceTagsRegistrationIDResponse, error) {
+	return j.DeviceGetTagsRegistrationIDContext(context.Background(), tag, registrationID)
+}
+
+// DeviceGetTagsRegistrationIDContext like DeviceGetTagsRegistrationID, with ctx for cancellation and deadline
+func (j *JPush) DeviceGetTagsRegistrationIDContext(ctx context.Context, tag string, registrationID string) (*DeviceTagsRegistrationIDResponse, error) {
 	url := j.GetURL("tag") + tag + "/registration_ids/" + registrationID
 
-	resp, err := j.request("GET", url, nil, nil)
+	resp, err := j.request(ctx, "GET", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -167,13 +198,18 @@ func (j *JPush) DeviceGetTagsRegistrationID(tag string, registrationID string) (
 
 // DevicePostTags modify device tag
 func (j *JPush) DevicePostTags(tag string, req *DeviceTagsRequest) (*DefaultResponse, error) {
+	return j.DevicePostTagsContext(context.Background(), tag, req)
+}
+
+// DevicePostTagsContext like DevicePostTags, with ctx for cancellation and deadline
+func (j *JPush) DevicePostTagsContext(ctx context.Context, tag string, req *DeviceTagsRequest) (*DefaultResponse, error) {
 	url := j.GetURL("tag") + tag
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -187,13 +223,18 @@ func (j *JPush) DevicePostTags(tag string, req *DeviceTagsRequest) (*DefaultResp
 
 // DeviceDeleteTags delete tag
 func (j *JPush) DeviceDeleteTags(tag string, platforms []string) (*DefaultResponse, error) {
+	return j.DeviceDeleteTagsContext(context.Background(), tag, platforms)
+}
+
+// DeviceDeleteTagsContext like DeviceDeleteTags, with ctx for cancellation and deadline
+func (j *JPush) DeviceDeleteTagsContext(ctx context.Context, tag string, platforms []string) (*DefaultResponse, error) {
 	url := j.GetURL("tag") + tag
 	params := make(map[string]string)
 	if len(platforms) > 0 {
 		params["platform"] = strings.Join(platforms, ",")
 	}
 
-	resp, err := j.request("DELETE", url, nil, params)
+	resp, err := j.request(ctx, "DELETE", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -207,12 +248,17 @@ func (j *JPush) DeviceDeleteTags(tag string, platforms []string) (*DefaultRespon
 
 // DevicePostStatus get devices status
 func (j *JPush) DevicePostStatus(req *DeviceStatusRequest) (map[string]DeviceStatusResponse, error) {
+	return j.DevicePostStatusContext(context.Background(), req)
+}
+
+// DevicePostStatusContext like DevicePostStatus, with ctx for cancellation and deadline
+func (j *JPush) DevicePostStatusContext(ctx context.Context, req *DeviceStatusRequest) (map[string]DeviceStatusResponse, error) {
 	url := j.GetURL("device") + "status/"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,193 @@
+package jpush
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// JPush api error codes
+// https://docs.jiguang.cn/jpush/server/push/rest_api_v3_push/
+const (
+	CodeInternalError      = 1000 // 系统内部错误
+	CodeMethodNotAllowed   = 1001 // 只支持 HTTP Post 方法
+	CodeMissingParam       = 1002 // 缺少了必须的参数
+	CodeInvalidParam       = 1003 // 参数值不合法
+	CodeAuthFailed         = 1004 // 验证失败
+	CodeBodyTooLarge       = 1005 // 消息体太大
+	CodeInvalidAppKey      = 1008 // app_key 参数非法
+	CodeUnsupportedKey     = 1009 // 推送对象中有不支持的 key
+	CodeNoTarget           = 1011 // 没有满足条件的推送目标
+	CodeHTTPSOnly          = 1020 // 只支持 HTTPS 请求
+	CodeInternalTimeout    = 1030 // 内部服务超时
+	CodeRateLimited        = 2002 // API 调用频率超出该应用的限制
+	CodeAppKeyRestricted   = 2003 // 该应用 appkey 已被限制调用 API 推送
+	CodeNoPermission       = 2004 // 无权限执行当前操作
+	CodeSendLimitExceeded  = 2005 // 信息发送量超出合理范围
+	CodeNotVIP             = 2006 // 非 VIP 用户
+	CodeAPIForbidden       = 2007 // 无权限调用此接口
+	CodeBroadcastLimited   = 2008 // 广播推送超出频率限制
+	CodePushRestricted     = 2009 // 推送请求被限制
+	CodeWithdrawDelivered  = 2030 // 撤回失败，消息已全部送达
+	CodeWithdrawTooOld     = 2031 // 撤回失败，只能撤回一天内的消息
+	CodeReportAuthFailed   = 3001 // Report API HTTP Basic authorization 失败
+	CodeReportInvalidMsgID = 3002 // Report API msg_ids 参数不存在或不合法
+	CodeReportTooManyIDs   = 3003 // Report API msg_ids 数目超过 100
+	CodeReportRateLimited  = 3004 // Report API 超过频率限制
+	CodeDeviceInternal     = 7000 // Device API 内部错误
+	CodeDeviceAuthEmpty    = 7001 // Device API 校验信息为空
+	CodeDeviceInvalidParam = 7002 // Device API 请求参数非法
+	CodeDeviceAuthFailed   = 7004 // Device API 校验失败
+	CodeScheduleInternal   = 8000 // Schedule API 内部错误
+	CodeScheduleInvalid    = 8101 // Schedule API 参数非法
+)
+
+// sentinel errors, use errors.Is to check an api error
+var (
+	ErrInternal         = errors.New("jpush: internal error")
+	ErrInvalidParam     = errors.New("jpush: invalid parameter")
+	ErrAuthFailed       = errors.New("jpush: authorization failed")
+	ErrBodyTooLarge     = errors.New("jpush: request body too large")
+	ErrInvalidAudience  = errors.New("jpush: no push target matched the audience")
+	ErrRateLimited      = errors.New("jpush: rate limit exceeded")
+	ErrForbidden        = errors.New("jpush: permission denied")
+	ErrAlreadyDelivered = errors.New("jpush: message already delivered, can not withdraw")
+	ErrWithdrawTooOld   = errors.New("jpush: message too old to withdraw")
+)
+
+// codeErrors map error code to sentinel error
+var codeErrors = map[int]error{
+	CodeInternalError:      ErrInternal,
+	CodeInternalTimeout:    ErrInternal,
+	CodeDeviceInternal:     ErrInternal,
+	CodeScheduleInternal:   ErrInternal,
+	CodeMissingParam:       ErrInvalidParam,
+	CodeInvalidParam:       ErrInvalidParam,
+	CodeUnsupportedKey:     ErrInvalidParam,
+	CodeReportInvalidMsgID: ErrInvalidParam,
+	CodeReportTooManyIDs:   ErrInvalidParam,
+	CodeDeviceInvalidParam: ErrInvalidParam,
+	CodeScheduleInvalid:    ErrInvalidParam,
+	CodeAuthFailed:         ErrAuthFailed,
+	CodeInvalidAppKey:      ErrAuthFailed,
+	CodeReportAuthFailed:   ErrAuthFailed,
+	CodeDeviceAuthEmpty:    ErrAuthFailed,
+	CodeDeviceAuthFailed:   ErrAuthFailed,
+	CodeBodyTooLarge:       ErrBodyTooLarge,
+	CodeNoTarget:           ErrInvalidAudience,
+	CodeRateLimited:        ErrRateLimited,
+	CodeReportRateLimited:  ErrRateLimited,
+	CodeBroadcastLimited:   ErrRateLimited,
+	CodeAppKeyRestricted:   ErrForbidden,
+	CodeNoPermission:       ErrForbidden,
+	CodeSendLimitExceeded:  ErrForbidden,
+	CodeNotVIP:             ErrForbidden,
+	CodeAPIForbidden:       ErrForbidden,
+	CodePushRestricted:     ErrForbidden,
+	CodeWithdrawDelivered:  ErrAlreadyDelivered,
+	CodeWithdrawTooOld:     ErrWithdrawTooOld,
+}
+
+// statusErrors map http status to sentinel error when no code returned
+var statusErrors = map[int]error{
+	http.StatusBadRequest:            ErrInvalidParam,
+	http.StatusUnauthorized:          ErrAuthFailed,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusRequestEntityTooLarge: ErrBodyTooLarge,
+	http.StatusTooManyRequests:       ErrRateLimited,
+	http.StatusInternalServerError:   ErrInternal,
+}
+
+// APIError error response from api with non 200 status
+type APIError struct {
+	StatusCode int         // HTTP 状态码
+	Code       int         // JPush 错误码，响应不是 JSON 时为 0
+	Message    string      // JPush 错误信息
+	Body       []byte      // 原始响应内容
+	Header     http.Header // 响应头
+	RateLimit  RateLimit   // 响应头中的频率限制
+}
+
+// newAPIError new api error from response and body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		Header:     resp.Header,
+	}
+	apiErr.RateLimit, _ = parseRateLimit(resp.Header)
+	var jErr ErrorResponse
+	if json.Unmarshal(body, &jErr) == nil {
+		apiErr.Code = jErr.Error.Code
+		apiErr.Message = jErr.Error.Message
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("JPush HTTP Error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("JPush Error %d: %s", e.Code, e.Message)
+}
+
+// Unwrap return the ErrorMessage of the api error
+func (e *APIError) Unwrap() error {
+	if e.Code == 0 {
+		return nil
+	}
+	return ErrorMessage{Code: e.Code, Message: e.Message}
+}
+
+// Is report whether the api error matches the sentinel error target
+func (e *APIError) Is(target error) bool {
+	if err, ok := codeErrors[e.Code]; ok {
+		return err == target
+	}
+	if err, ok := statusErrors[e.StatusCode]; ok {
+		return err == target
+	}
+	return e.StatusCode > 500 && target == ErrInternal
+}
+
+// IsRateLimited check if err is caused by the rate limit
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrThrottled)
+}
+
+// IsAuthFailure check if err is caused by authorization failure
+func IsAuthFailure(err error) bool {
+	return errors.Is(err, ErrAuthFailed)
+}
+
+// IsInvalidAudience check if err is caused by no push target matched
+func IsInvalidAudience(err error) bool {
+	return errors.Is(err, ErrInvalidAudience)
+}
+
+// IsRetryable check if the request failed with err may succeed on retry
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrThrottled) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// transport error, no response received
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if errors.Is(err, ErrInternal) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	return apiErr.StatusCode >= 500
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/deaswang/jpush-api-golang"
 )
@@ -14,26 +15,16 @@ const (
 
 func main() {
 	j := jpush.NewJPush(Appkey, masterSecret)
-	aud := &jpush.PushAudience{}
-	aud.SetAll(true)
-	aud.Aud = &jpush.Audience{}
-	aud.Aud.Tag = []string{"tag"}
-	req := &jpush.PushRequest{
-		Platform: &jpush.Platform{Platforms: []string{"android", "ios"}},
-		Audience: aud,
-		Notification: &jpush.PushNotification{
-			Alert: "test alert",
-			Android: &jpush.NotificationAndroid{
-				Alert:     "alert",
-				Title:     "title",
-				BuilderID: 0,
-				Priority:  1,
-				AlertType: 7,
-			},
-		},
-		Options: &jpush.PushOptions{
-			TimeToLive: 0,
-		},
+	req, err := jpush.NewPush().Android().IOS().
+		ToTags("tag").
+		Alert("test alert").
+		AndroidAlert("alert").
+		AndroidTitle("title").
+		TTL(0).
+		Build()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
 	}
 	ret, err := j.Push(req)
 	if err != nil {
@@ -41,5 +32,26 @@ func main() {
 		return
 	}
 	fmt.Println("result:", ret)
+
+	// update the ios live activity
+	req, err = jpush.NewPush().IOS().
+		ToLiveActivity("live-activity-id").
+		LiveActivity(&jpush.LiveActivityIOS{
+			Event:        jpush.LiveActivityUpdate,
+			ContentState: map[string]interface{}{"eta": "10 min"},
+			Alert:        &jpush.LiveActivityAlert{Title: "order", Body: "arriving soon"},
+			StaleDate:    time.Now().Add(time.Hour).Unix(),
+		}).
+		Build()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	ret, err = j.Push(req)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Println("live activity result:", ret)
 	return
 }
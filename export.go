@@ -0,0 +1,231 @@
+package jpush
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportFormat report export format
+type ExportFormat string
+
+// report export formats
+const (
+	ExportCSV   ExportFormat = "csv"   // 首行为列名的 CSV
+	ExportJSONL ExportFormat = "jsonl" // 每行一个 JSON 对象
+)
+
+// exportField a column of the exported row, nil value is exported as an
+// empty cell in CSV and null in JSONL
+type exportField struct {
+	name  string
+	value interface{}
+}
+
+// exporter write rows of the same columns in format
+type exporter struct {
+	w      io.Writer
+	format ExportFormat
+	csv    *csv.Writer
+	header bool
+}
+
+// newExporter new exporter of format
+func newExporter(w io.Writer, format ExportFormat) (*exporter, error) {
+	switch format {
+	case ExportCSV:
+		return &exporter{w: w, format: format, csv: csv.NewWriter(w)}, nil
+	case ExportJSONL:
+		return &exporter{w: w, format: format}, nil
+	}
+	return nil, fmt.Errorf("jpush: unknown export format %q", format)
+}
+
+// write write one row
+func (e *exporter) write(row []exportField) error {
+	if e.format == ExportCSV {
+		if err := e.writeHeader(row); err != nil {
+			return err
+		}
+		values := make([]string, len(row))
+		for i, f := range row {
+			if f.value != nil {
+				values[i] = fmt.Sprint(f.value)
+			}
+		}
+		return e.csv.Write(values)
+	}
+	// keep the column order, json.Marshal of map sorts the keys
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range row {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// writeHeader write the csv header once with the column names of row
+func (e *exporter) writeHeader(row []exportField) error {
+	if e.csv == nil || e.header {
+		return nil
+	}
+	names := make([]string, len(row))
+	for i, f := range row {
+		names[i] = f.name
+	}
+	e.header = true
+	return e.csv.Write(names)
+}
+
+// flush write the csv header of empty row when no row written, and flush
+// the buffered csv rows
+func (e *exporter) flush(empty []exportField) error {
+	if e.csv == nil {
+		return nil
+	}
+	if err := e.writeHeader(empty); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// optional get v when the section exists, or nil
+func optional(exists bool, v int) interface{} {
+	if !exists {
+		return nil
+	}
+	return v
+}
+
+// ExportMessages write the messages report in format, one row of each msg
+// id with android, ios and winphone columns
+func ExportMessages(w io.Writer, format ExportFormat, stats []ReportMessagesResponse) error {
+	e, err := newExporter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		if err := e.write(messagesRow(&stat)); err != nil {
+			return err
+		}
+	}
+	return e.flush(messagesRow(&ReportMessagesResponse{}))
+}
+
+// messagesRow get the export row of messages stat
+func messagesRow(stat *ReportMessagesResponse) []exportField {
+	a, i, wp := stat.Android, stat.IOS, stat.Wp
+	if a == nil {
+		a = &ReportAndroidMessage{}
+	}
+	if i == nil {
+		i = &ReportIOSMessage{}
+	}
+	if wp == nil {
+		wp = &ReportWpMessage{}
+	}
+	return []exportField{
+		{"msg_id", string(stat.MsgID)},
+		{"android_target", optional(stat.Android != nil, a.Target)},
+		{"android_online_push", optional(stat.Android != nil, a.OnlinePush)},
+		{"android_received", optional(stat.Android != nil, a.Received)},
+		{"android_click", optional(stat.Android != nil, a.Click)},
+		{"android_msg_click", optional(stat.Android != nil, a.MsgClick)},
+		{"ios_target", optional(stat.IOS != nil, i.Target)},
+		{"ios_received", optional(stat.IOS != nil, i.Received)},
+		{"ios_apns_target", optional(stat.IOS != nil, i.ApnsTarget)},
+		{"ios_apns_sent", optional(stat.IOS != nil, i.ApnsSent)},
+		{"ios_apns_received", optional(stat.IOS != nil, i.ApnsReceived)},
+		{"ios_click", optional(stat.IOS != nil, i.Click)},
+		{"winphone_mpns_target", optional(stat.Wp != nil, wp.MpnsTarget)},
+		{"winphone_mpns_sent", optional(stat.Wp != nil, wp.MpnsSent)},
+		{"winphone_click", optional(stat.Wp != nil, wp.Click)},
+	}
+}
+
+// ExportReceived write the received report in format, one row of each msg id
+func ExportReceived(w io.Writer, format ExportFormat, stats []ReportReceivedResponse) error {
+	e, err := newExporter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		if err := e.write(receivedRow(&stat)); err != nil {
+			return err
+		}
+	}
+	return e.flush(receivedRow(&ReportReceivedResponse{}))
+}
+
+// receivedRow get the export row of received stat
+func receivedRow(stat *ReportReceivedResponse) []exportField {
+	return []exportField{
+		{"msg_id", string(stat.MsgID)},
+		{"android_received", stat.AndroidReceived},
+		{"ios_apns_sent", stat.IOSApnsSent},
+		{"ios_apns_received", stat.IOSApnsReceived},
+		{"ios_msg_received", stat.IOSMsgReceived},
+		{"wp_mpns_sent", stat.WpMpnsSent},
+	}
+}
+
+// ExportUsers write the users reports in format, one row of each time
+// unit, pass the reports of consecutive windows to export a date range
+func ExportUsers(w io.Writer, format ExportFormat, reports ...*ReportUsersResponse) error {
+	e, err := newExporter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		for _, item := range report.Items {
+			if err := e.write(usersRow(report.TimeUnit, &item)); err != nil {
+				return err
+			}
+		}
+	}
+	return e.flush(usersRow("", &ReportUser{}))
+}
+
+// usersRow get the export row of users stat, time is formatted of timeUnit
+func usersRow(timeUnit TimeUnit, item *ReportUser) []exportField {
+	var t interface{}
+	if item.Time != nil {
+		t = time.Time(*item.Time).In(Beijing).Format(timeUnit.layout())
+	}
+	a, i := item.Android, item.IOS
+	if a == nil {
+		a = &ReportUserAndroid{}
+	}
+	if i == nil {
+		i = &ReportUserIOS{}
+	}
+	return []exportField{
+		{"time_unit", string(timeUnit)},
+		{"time", t},
+		{"android_new", optional(item.Android != nil, a.New)},
+		{"android_active", optional(item.Android != nil, a.Active)},
+		{"android_online", optional(item.Android != nil, a.Online)},
+		{"ios_new", optional(item.IOS != nil, i.New)},
+		{"ios_active", optional(item.IOS != nil, i.Active)},
+		{"ios_online", optional(item.IOS != nil, i.Online)},
+	}
+}
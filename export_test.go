@@ -0,0 +1,127 @@
+package jpush_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+func TestExportMessages(t *testing.T) {
+	stats := []jpush.ReportMessagesResponse{
+		{
+			MsgID:   "18100000000000001",
+			Android: &jpush.ReportAndroidMessage{Target: 10, OnlinePush: 8, Received: 7, Click: 2, MsgClick: 1},
+		},
+		{
+			MsgID: "18100000000000002",
+			IOS:   &jpush.ReportIOSMessage{Target: 5, Received: 4, ApnsTarget: 5, ApnsSent: 5, ApnsReceived: 3, Click: 1},
+		},
+	}
+	tests := []struct {
+		format jpush.ExportFormat
+		stats  []jpush.ReportMessagesResponse
+		want   string
+	}{
+		{jpush.ExportCSV, stats, "" +
+			"msg_id,android_target,android_online_push,android_received,android_click,android_msg_click,ios_target,ios_received,ios_apns_target,ios_apns_sent,ios_apns_received,ios_click,winphone_mpns_target,winphone_mpns_sent,winphone_click\n" +
+			"18100000000000001,10,8,7,2,1,,,,,,,,,\n" +
+			"18100000000000002,,,,,,5,4,5,5,3,1,,,\n"},
+		{jpush.ExportJSONL, stats, "" +
+			`{"msg_id":"18100000000000001","android_target":10,"android_online_push":8,"android_received":7,"android_click":2,"android_msg_click":1,"ios_target":null,"ios_received":null,"ios_apns_target":null,"ios_apns_sent":null,"ios_apns_received":null,"ios_click":null,"winphone_mpns_target":null,"winphone_mpns_sent":null,"winphone_click":null}` + "\n" +
+			`{"msg_id":"18100000000000002","android_target":null,"android_online_push":null,"android_received":null,"android_click":null,"android_msg_click":null,"ios_target":5,"ios_received":4,"ios_apns_target":5,"ios_apns_sent":5,"ios_apns_received":3,"ios_click":1,"winphone_mpns_target":null,"winphone_mpns_sent":null,"winphone_click":null}` + "\n"},
+		{jpush.ExportCSV, nil, "" +
+			"msg_id,android_target,android_online_push,android_received,android_click,android_msg_click,ios_target,ios_received,ios_apns_target,ios_apns_sent,ios_apns_received,ios_click,winphone_mpns_target,winphone_mpns_sent,winphone_click\n"},
+		{jpush.ExportJSONL, nil, ""},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := jpush.ExportMessages(&buf, tt.format, tt.stats); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s of %d stats:\ngot\n%s\nwant\n%s", tt.format, len(tt.stats), got, tt.want)
+		}
+	}
+}
+
+func TestExportReceived(t *testing.T) {
+	stats := []jpush.ReportReceivedResponse{
+		{MsgID: "18100000000000001", AndroidReceived: 7, IOSApnsSent: 5, IOSApnsReceived: 3, IOSMsgReceived: 2},
+	}
+	tests := []struct {
+		format jpush.ExportFormat
+		stats  []jpush.ReportReceivedResponse
+		want   string
+	}{
+		{jpush.ExportCSV, stats, "" +
+			"msg_id,android_received,ios_apns_sent,ios_apns_received,ios_msg_received,wp_mpns_sent\n" +
+			"18100000000000001,7,5,3,2,0\n"},
+		{jpush.ExportJSONL, stats, "" +
+			`{"msg_id":"18100000000000001","android_received":7,"ios_apns_sent":5,"ios_apns_received":3,"ios_msg_received":2,"wp_mpns_sent":0}` + "\n"},
+		{jpush.ExportCSV, nil, "msg_id,android_received,ios_apns_sent,ios_apns_received,ios_msg_received,wp_mpns_sent\n"},
+		{jpush.ExportJSONL, nil, ""},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := jpush.ExportReceived(&buf, tt.format, tt.stats); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s of %d stats:\ngot\n%s\nwant\n%s", tt.format, len(tt.stats), got, tt.want)
+		}
+	}
+}
+
+func TestExportUsers(t *testing.T) {
+	reportTime := func(year int, month time.Month, day, hour int) *jpush.ReportTime {
+		t := jpush.ReportTime(time.Date(year, month, day, hour, 0, 0, 0, jpush.Beijing))
+		return &t
+	}
+	hour := &jpush.ReportUsersResponse{
+		TimeUnit: jpush.TimeUnitHour,
+		Items: []jpush.ReportUser{
+			{Time: reportTime(2024, 3, 5, 9), Android: &jpush.ReportUserAndroid{New: 1, Active: 2, Online: 3}},
+			{Time: reportTime(2024, 3, 5, 10), IOS: &jpush.ReportUserIOS{New: 4, Active: 5, Online: 6}},
+		},
+	}
+	month := &jpush.ReportUsersResponse{
+		TimeUnit: jpush.TimeUnitMonth,
+		Items: []jpush.ReportUser{
+			{Time: reportTime(2024, 3, 1, 0), Android: &jpush.ReportUserAndroid{New: 7}, IOS: &jpush.ReportUserIOS{Active: 8}},
+		},
+	}
+	tests := []struct {
+		format  jpush.ExportFormat
+		reports []*jpush.ReportUsersResponse
+		want    string
+	}{
+		{jpush.ExportCSV, []*jpush.ReportUsersResponse{hour, nil, month}, "" +
+			"time_unit,time,android_new,android_active,android_online,ios_new,ios_active,ios_online\n" +
+			"HOUR,2024-03-05 09,1,2,3,,,\n" +
+			"HOUR,2024-03-05 10,,,,4,5,6\n" +
+			"MONTH,2024-03,7,0,0,0,8,0\n"},
+		{jpush.ExportJSONL, []*jpush.ReportUsersResponse{hour, nil, month}, "" +
+			`{"time_unit":"HOUR","time":"2024-03-05 09","android_new":1,"android_active":2,"android_online":3,"ios_new":null,"ios_active":null,"ios_online":null}` + "\n" +
+			`{"time_unit":"HOUR","time":"2024-03-05 10","android_new":null,"android_active":null,"android_online":null,"ios_new":4,"ios_active":5,"ios_online":6}` + "\n" +
+			`{"time_unit":"MONTH","time":"2024-03","android_new":7,"android_active":0,"android_online":0,"ios_new":0,"ios_active":8,"ios_online":0}` + "\n"},
+		{jpush.ExportCSV, nil, "time_unit,time,android_new,android_active,android_online,ios_new,ios_active,ios_online\n"},
+		{jpush.ExportJSONL, nil, ""},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := jpush.ExportUsers(&buf, tt.format, tt.reports...); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s of %d reports:\ngot\n%s\nwant\n%s", tt.format, len(tt.reports), got, tt.want)
+		}
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if err := jpush.ExportReceived(&bytes.Buffer{}, "xml", nil); err == nil {
+		t.Error("want unknown format error")
+	}
+}
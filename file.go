@@ -0,0 +1,151 @@
+package jpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// file types of the file api
+const (
+	FileTypeAlias          = "alias"
+	FileTypeRegistrationID = "registration_id"
+)
+
+// AudienceFile define the file audience
+type AudienceFile struct {
+	FileID string `json:"file_id"`
+}
+
+// FileUploadResponse file upload response
+type FileUploadResponse struct {
+	FileID string `json:"file_id"`
+}
+
+// FileResponse file info response
+type FileResponse struct {
+	FileID     string `json:"file_id"`
+	Type       string `json:"type"`
+	CreateTime string `json:"create_time"`
+}
+
+// FileListResponse file list response
+type FileListResponse struct {
+	TotalCount int            `json:"total_count"`
+	Files      []FileResponse `json:"files"`
+}
+
+// FileUpload upload file of registration ids or aliases, one per line
+// POST /v3/files/{type}
+func (j *JPush) FileUpload(fileType string, r io.Reader) (*FileUploadResponse, error) {
+	return j.FileUploadContext(context.Background(), fileType, r)
+}
+
+// FileUploadContext like FileUpload, with ctx for cancellation and deadline
+func (j *JPush) FileUploadContext(ctx context.Context, fileType string, r io.Reader) (*FileUploadResponse, error) {
+	url := j.GetURL("push") + "files/" + fileType
+
+	resp, err := j.upload(ctx, "POST", url, "filename", fileType+".txt", r, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(FileUploadResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// FileList get all files
+// GET /v3/files
+func (j *JPush) FileList() (*FileListResponse, error) {
+	return j.FileListContext(context.Background())
+}
+
+// FileListContext like FileList, with ctx for cancellation and deadline
+func (j *JPush) FileListContext(ctx context.Context) (*FileListResponse, error) {
+	url := j.GetURL("push") + "files"
+
+	resp, err := j.request(ctx, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(FileListResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// FileGet get file by id
+// GET /v3/files/{file_id}
+func (j *JPush) FileGet(fileID string) (*FileResponse, error) {
+	return j.FileGetContext(context.Background(), fileID)
+}
+
+// FileGetContext like FileGet, with ctx for cancellation and deadline
+func (j *JPush) FileGetContext(ctx context.Context, fileID string) (*FileResponse, error) {
+	url := j.GetURL("push") + "files/" + fileID
+
+	resp, err := j.request(ctx, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(FileResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// FileDelete delete file by id
+// DELETE /v3/files/{file_id}
+func (j *JPush) FileDelete(fileID string) (*DefaultResponse, error) {
+	return j.FileDeleteContext(context.Background(), fileID)
+}
+
+// FileDeleteContext like FileDelete, with ctx for cancellation and deadline
+func (j *JPush) FileDeleteContext(ctx context.Context, fileID string) (*DefaultResponse, error) {
+	url := j.GetURL("push") + "files/" + fileID
+
+	resp, err := j.request(ctx, "DELETE", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(DefaultResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// PushFile push to the file audience, set Audience.Aud.File of req
+// POST /v3/push/file
+func (j *JPush) PushFile(req *PushRequest) (*PushResponse, error) {
+	return j.PushFileContext(context.Background(), req)
+}
+
+// PushFileContext like PushFile, with ctx for cancellation and deadline
+func (j *JPush) PushFileContext(ctx context.Context, req *PushRequest) (*PushResponse, error) {
+	ctx = retryIfCid(ctx, req.Cid)
+	url := j.GetURL("push") + "push/file"
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(PushResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,96 @@
+package jpush_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+func TestFileUploadAndPush(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	client := srv.JPushClient()
+
+	ret, err := client.FileUpload(jpush.FileTypeRegistrationID, strings.NewReader("rid1\nrid2\n\nrid3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := srv.File(ret.FileID)
+	if !ok {
+		t.Fatalf("file %s not uploaded", ret.FileID)
+	}
+	if got := strings.Join(f.Targets, ","); got != "rid1,rid2,rid3" || f.Type != jpush.FileTypeRegistrationID {
+		t.Errorf("got %s targets %s, want registration_id targets rid1,rid2,rid3", f.Type, got)
+	}
+
+	req, err := jpush.NewPush().Android().ToFile(ret.FileID).Alert("hello").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.PushFile(req); err != nil {
+		t.Fatal(err)
+	}
+	push, ok := srv.LastPush()
+	if !ok || push.Endpoint != jpushtest.EndpointFilePush || push.Request.Audience.Aud.File.FileID != ret.FileID {
+		t.Errorf("got push %+v, want file push of %s", push, ret.FileID)
+	}
+}
+
+// endlessReader read targets forever, count the reads
+type endlessReader struct {
+	reads int32
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	atomic.AddInt32(&r.reads, 1)
+	line := []byte("registration_id\n")
+	n := 0
+	for n+len(line) <= len(p) {
+		n += copy(p[n:], line)
+	}
+	return n, nil
+}
+
+// uploadWriterRunning check if the multipart writer goroutine of upload is running
+func uploadWriterRunning() bool {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	return bytes.Contains(buf, []byte("jpush.(*JPush).upload.func"))
+}
+
+func TestFileUploadServerFailFirst(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.InjectError(jpushtest.EndpointFile, jpushtest.Fault{Status: 500, Code: jpush.CodeInternalError, Message: "internal error"})
+	transport := &countTransport{path: "/v3/files/" + jpush.FileTypeAlias}
+	client := srv.JPushClient(jpush.WithTransport(transport), jpush.WithRetryPolicy(&jpush.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := client.FileUploadContext(ctx, jpush.FileTypeAlias, &endlessReader{})
+	var apiErr *jpush.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Fatalf("got %v, want the 500 error of server", err)
+	}
+	if n := atomic.LoadInt32(&transport.count); n != 1 {
+		t.Errorf("got %d calls, want upload not retried", n)
+	}
+	deadline := time.Now().Add(time.Second)
+	for uploadWriterRunning() {
+		if time.Now().After(deadline) {
+			t.Fatal("multipart writer goroutine not exit")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,122 @@
+package jpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// ImageType define image type of the images api
+type ImageType int
+
+// image types
+const (
+	ImageBigPicture ImageType = 1 // 大图片，用于 NotificationAndroid.BigPicPath
+	ImageLargeIcon  ImageType = 2 // 大图标，用于 NotificationAndroid.LargeIcon
+)
+
+// ImageURLRequest upload or update image by urls, the vendor urls are
+// optional and default to ImageURL
+type ImageURLRequest struct {
+	ImageType      ImageType `json:"image_type,omitempty"`
+	ImageURL       string    `json:"image_url,omitempty"`
+	XiaomiImageURL string    `json:"xiaomi_image_url,omitempty"`
+	HuaweiImageURL string    `json:"huawei_image_url,omitempty"`
+	HonorImageURL  string    `json:"honor_image_url,omitempty"`
+	OppoImageURL   string    `json:"oppo_image_url,omitempty"`
+	FcmImageURL    string    `json:"fcm_image_url,omitempty"`
+}
+
+// ImageResponse image response, set MediaID to BigPicPath or LargeIcon
+// of NotificationAndroid
+type ImageResponse struct {
+	MediaID        string `json:"media_id"`
+	ImageURL       string `json:"image_url,omitempty"`
+	XiaomiImageURL string `json:"xiaomi_image_url,omitempty"`
+	HuaweiImageURL string `json:"huawei_image_url,omitempty"`
+	HonorImageURL  string `json:"honor_image_url,omitempty"`
+	OppoImageURL   string `json:"oppo_image_url,omitempty"`
+	FcmImageURL    string `json:"fcm_image_url,omitempty"`
+}
+
+// ImageUploadByURL upload image by urls
+// POST /v3/images/byurls
+func (j *JPush) ImageUploadByURL(req *ImageURLRequest) (*ImageResponse, error) {
+	return j.ImageUploadByURLContext(context.Background(), req)
+}
+
+// ImageUploadByURLContext like ImageUploadByURL, with ctx for cancellation and deadline
+func (j *JPush) ImageUploadByURLContext(ctx context.Context, req *ImageURLRequest) (*ImageResponse, error) {
+	return j.imageURL(ctx, "POST", j.GetURL("push")+"images/byurls", req)
+}
+
+// ImageUpdateByURL update image of media id by urls
+// PUT /v3/images/byurls/{media_id}
+func (j *JPush) ImageUpdateByURL(mediaID string, req *ImageURLRequest) (*ImageResponse, error) {
+	return j.ImageUpdateByURLContext(context.Background(), mediaID, req)
+}
+
+// ImageUpdateByURLContext like ImageUpdateByURL, with ctx for cancellation and deadline
+func (j *JPush) ImageUpdateByURLContext(ctx context.Context, mediaID string, req *ImageURLRequest) (*ImageResponse, error) {
+	return j.imageURL(ctx, "PUT", j.GetURL("push")+"images/byurls/"+mediaID, req)
+}
+
+// ImageUploadByFile upload image file
+// POST /v3/images/byfiles
+func (j *JPush) ImageUploadByFile(imageType ImageType, filename string, r io.Reader) (*ImageResponse, error) {
+	return j.ImageUploadByFileContext(context.Background(), imageType, filename, r)
+}
+
+// ImageUploadByFileContext like ImageUploadByFile, with ctx for cancellation and deadline
+func (j *JPush) ImageUploadByFileContext(ctx context.Context, imageType ImageType, filename string, r io.Reader) (*ImageResponse, error) {
+	return j.imageFile(ctx, "POST", j.GetURL("push")+"images/byfiles", imageType, filename, r)
+}
+
+// ImageUpdateByFile update image of media id by file
+// PUT /v3/images/byfiles/{media_id}
+func (j *JPush) ImageUpdateByFile(mediaID string, filename string, r io.Reader) (*ImageResponse, error) {
+	return j.ImageUpdateByFileContext(context.Background(), mediaID, filename, r)
+}
+
+// ImageUpdateByFileContext like ImageUpdateByFile, with ctx for cancellation and deadline
+func (j *JPush) ImageUpdateByFileContext(ctx context.Context, mediaID string, filename string, r io.Reader) (*ImageResponse, error) {
+	return j.imageFile(ctx, "PUT", j.GetURL("push")+"images/byfiles/"+mediaID, 0, filename, r)
+}
+
+// imageURL send image url request
+func (j *JPush) imageURL(ctx context.Context, method, url string, req *ImageURLRequest) (*ImageResponse, error) {
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.request(ctx, method, url, bytes.NewReader(buf), nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(ImageResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// imageFile stream image file request
+func (j *JPush) imageFile(ctx context.Context, method, url string, imageType ImageType, filename string, r io.Reader) (*ImageResponse, error) {
+	var fields map[string]string
+	if imageType != 0 {
+		fields = map[string]string{"image_type": strconv.Itoa(int(imageType))}
+	}
+	resp, err := j.upload(ctx, method, url, "file", filename, r, fields)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(ImageResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,43 @@
+package jpush_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+func TestImageByFile(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	client := srv.JPushClient()
+
+	ret, err := client.ImageUploadByFile(jpush.ImageLargeIcon, "icon.png", strings.NewReader("icon data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ret.MediaID, "jgmedia-") {
+		t.Fatalf("got media id %q", ret.MediaID)
+	}
+	img, ok := srv.Image(ret.MediaID)
+	if !ok || img.ImageType != jpush.ImageLargeIcon || img.Filename != "icon.png" || string(img.Data) != "icon data" {
+		t.Errorf("got image %+v, want large icon icon.png", img)
+	}
+
+	updated, err := client.ImageUpdateByFile(ret.MediaID, "icon2.png", strings.NewReader("new data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.MediaID != ret.MediaID {
+		t.Errorf("update got media id %q, want %q", updated.MediaID, ret.MediaID)
+	}
+	img, _ = srv.Image(ret.MediaID)
+	if img.ImageType != jpush.ImageLargeIcon || img.Filename != "icon2.png" || string(img.Data) != "new data" {
+		t.Errorf("got updated image %+v, want icon2.png of large icon", img)
+	}
+
+	if _, err := client.ImageUpdateByFile("jgmedia-0-unknown", "icon.png", strings.NewReader("data")); err == nil {
+		t.Error("update unknown media: want error")
+	}
+}
@@ -0,0 +1,42 @@
+package jpushtest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// handleAdmin handle app, app/{appkey}/delete and app/{appkey}/certificate
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "only support POST method")
+		return
+	}
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "app":
+		req := new(jpush.AdminAppRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		s.mu.Lock()
+		appKey := "app" + s.newID()
+		s.mu.Unlock()
+		writeJSON(w, jpush.AdminAppResponse{
+			AppKey:         appKey,
+			AndroidPackage: req.AndroidPackage,
+			IsNewCreated:   true,
+		})
+	case len(parts) == 3 && parts[0] == "app" && parts[2] == "delete":
+		writeJSON(w, jpush.AdminSuccessResponse{Success: "OK"})
+	case len(parts) == 3 && parts[0] == "app" && parts[2] == "certificate":
+		req := new(jpush.AdminCertificateRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		writeJSON(w, jpush.AdminSuccessResponse{Success: "OK"})
+	default:
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+	}
+}
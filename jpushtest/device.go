@@ -0,0 +1,237 @@
+package jpushtest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// AddDevice add or replace a device
+func (s *Server) AddDevice(device Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := device
+	d.Tags = append([]string(nil), device.Tags...)
+	s.devices[device.RegistrationID] = &d
+}
+
+// Device get device by registration id
+func (s *Server) Device(registrationID string) (Device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[registrationID]
+	if !ok {
+		return Device{}, false
+	}
+	device := *d
+	device.Tags = append([]string(nil), d.Tags...)
+	return device, true
+}
+
+// device get or create device, must be called with lock
+func (s *Server) device(registrationID string) *Device {
+	d, ok := s.devices[registrationID]
+	if !ok {
+		d = &Device{RegistrationID: registrationID}
+		s.devices[registrationID] = d
+	}
+	return d
+}
+
+// hasTag check if tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// removeTag remove tag from tags
+func removeTag(tags []string, tag string) []string {
+	ret := tags[:0]
+	for _, t := range tags {
+		if t != tag {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
+
+// matchPlatform check if device platform in the platform query param
+func matchPlatform(d *Device, platforms string) bool {
+	if platforms == "" || d.Platform == "" {
+		return true
+	}
+	for _, p := range strings.Split(platforms, ",") {
+		if p == d.Platform {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDevice handle devices/{registration_id} and devices/status/
+func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request, path string) {
+	if strings.TrimSuffix(path, "/") == "status" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "only support POST method")
+			return
+		}
+		req := new(jpush.DeviceStatusRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		ret := make(map[string]jpush.DeviceStatusResponse)
+		s.mu.Lock()
+		for _, id := range req.RegistrationIDs {
+			if d, ok := s.devices[id]; ok {
+				ret[id] = jpush.DeviceStatusResponse{Online: d.Online}
+			}
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+		return
+	}
+	if path == "" || strings.Contains(path, "/") {
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "registration id is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		d := s.device(path)
+		ret := jpush.DeviceRegistrationIDResponse{
+			Tags:   append([]string{}, d.Tags...),
+			Alias:  d.Alias,
+			Mobile: d.Mobile,
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case http.MethodPost:
+		req := new(jpush.DeviceRegistrationIDRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		s.mu.Lock()
+		d := s.device(path)
+		if req.Tags != nil {
+			for _, tag := range req.Tags.Add {
+				if !hasTag(d.Tags, tag) {
+					d.Tags = append(d.Tags, tag)
+				}
+			}
+			for _, tag := range req.Tags.Remove {
+				d.Tags = removeTag(d.Tags, tag)
+			}
+		}
+		if req.Alias != "" {
+			d.Alias = req.Alias
+		}
+		if req.Mobile != "" {
+			d.Mobile = req.Mobile
+		}
+		s.mu.Unlock()
+		writeJSON(w, jpush.DefaultResponse{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleAlias handle aliases/{alias}
+func (s *Server) handleAlias(w http.ResponseWriter, r *http.Request, path string) {
+	if path == "" || strings.Contains(path, "/") {
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "alias is required")
+		return
+	}
+	platforms := r.URL.Query().Get("platform")
+	switch r.Method {
+	case http.MethodGet:
+		ret := jpush.DeviceAliasResponse{RegistrationIDs: []string{}}
+		s.mu.Lock()
+		for id, d := range s.devices {
+			if d.Alias == path && matchPlatform(d, platforms) {
+				ret.RegistrationIDs = append(ret.RegistrationIDs, id)
+			}
+		}
+		s.mu.Unlock()
+		sort.Strings(ret.RegistrationIDs)
+		writeJSON(w, ret)
+	case http.MethodDelete:
+		s.mu.Lock()
+		for _, d := range s.devices {
+			if d.Alias == path && matchPlatform(d, platforms) {
+				d.Alias = ""
+			}
+		}
+		s.mu.Unlock()
+		writeJSON(w, jpush.DefaultResponse{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleTag handle tags/, tags/{tag} and tags/{tag}/registration_ids/{registration_id}
+func (s *Server) handleTag(w http.ResponseWriter, r *http.Request, path string) {
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		set := make(map[string]bool)
+		s.mu.Lock()
+		for _, d := range s.devices {
+			for _, tag := range d.Tags {
+				set[tag] = true
+			}
+		}
+		s.mu.Unlock()
+		ret := jpush.DeviceTagsListResponse{Tags: []string{}}
+		for tag := range set {
+			ret.Tags = append(ret.Tags, tag)
+		}
+		sort.Strings(ret.Tags)
+		writeJSON(w, ret)
+	case len(parts) == 3 && parts[1] == "registration_ids" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		d, ok := s.devices[parts[2]]
+		result := ok && hasTag(d.Tags, parts[0])
+		s.mu.Unlock()
+		writeJSON(w, jpush.DeviceTagsRegistrationIDResponse{Result: result})
+	case len(parts) == 1 && path != "" && r.Method == http.MethodPost:
+		req := new(jpush.DeviceTagsRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		s.mu.Lock()
+		if req.RegistrationIDs != nil {
+			for _, id := range req.RegistrationIDs.Add {
+				d := s.device(id)
+				if !hasTag(d.Tags, path) {
+					d.Tags = append(d.Tags, path)
+				}
+			}
+			for _, id := range req.RegistrationIDs.Remove {
+				if d, ok := s.devices[id]; ok {
+					d.Tags = removeTag(d.Tags, path)
+				}
+			}
+		}
+		s.mu.Unlock()
+		writeJSON(w, jpush.DefaultResponse{})
+	case len(parts) == 1 && path != "" && r.Method == http.MethodDelete:
+		platforms := r.URL.Query().Get("platform")
+		s.mu.Lock()
+		for _, d := range s.devices {
+			if matchPlatform(d, platforms) {
+				d.Tags = removeTag(d.Tags, path)
+			}
+		}
+		s.mu.Unlock()
+		writeJSON(w, jpush.DefaultResponse{})
+	default:
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+	}
+}
@@ -0,0 +1,141 @@
+package jpushtest
+
+import (
+	"bufio"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// File uploaded file of registration ids or aliases
+type File struct {
+	FileID     string
+	Type       string
+	Targets    []string
+	CreateTime time.Time
+}
+
+// File get uploaded file by id
+func (s *Server) File(fileID string) (File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[fileID]
+	if !ok {
+		return File{}, false
+	}
+	file := *f
+	file.Targets = append([]string(nil), f.Targets...)
+	return file, true
+}
+
+// fileResponse convert file to api response, must be called with lock
+func fileResponse(f *File) jpush.FileResponse {
+	return jpush.FileResponse{
+		FileID:     f.FileID,
+		Type:       f.Type,
+		CreateTime: f.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// checkFileAudience check the file audience of push/file request
+func (s *Server) checkFileAudience(w http.ResponseWriter, req *jpush.PushRequest) bool {
+	aud := req.Audience.Aud
+	if aud == nil || aud.File == nil || aud.File.FileID == "" {
+		writeError(w, http.StatusBadRequest, jpush.CodeMissingParam, "audience file is required")
+		return false
+	}
+	s.mu.Lock()
+	_, ok := s.files[aud.File.FileID]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "file "+aud.File.FileID+" not exist")
+		return false
+	}
+	return true
+}
+
+// handleFile handle files, files/{type} and files/{file_id}
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		ret := jpush.FileListResponse{Files: []jpush.FileResponse{}}
+		for _, f := range s.files {
+			ret.Files = append(ret.Files, fileResponse(f))
+		}
+		s.mu.Unlock()
+		sort.Slice(ret.Files, func(i, j int) bool { return ret.Files[i].FileID < ret.Files[j].FileID })
+		ret.TotalCount = len(ret.Files)
+		writeJSON(w, ret)
+	case (path == jpush.FileTypeAlias || path == jpush.FileTypeRegistrationID) && r.Method == http.MethodPost:
+		s.handleFileUpload(w, r, path)
+	case path != "" && !strings.Contains(path, "/") && r.Method == http.MethodGet:
+		s.mu.Lock()
+		f, ok := s.files[path]
+		var ret jpush.FileResponse
+		if ok {
+			ret = fileResponse(f)
+		}
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "file "+path+" not exist")
+			return
+		}
+		writeJSON(w, ret)
+	case path != "" && !strings.Contains(path, "/") && r.Method == http.MethodDelete:
+		s.mu.Lock()
+		_, ok := s.files[path]
+		delete(s.files, path)
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "file "+path+" not exist")
+			return
+		}
+		writeJSON(w, jpush.DefaultResponse{})
+	default:
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+	}
+}
+
+// handleFileUpload read the multipart filename field, one target per line
+func (s *Server) handleFileUpload(w http.ResponseWriter, r *http.Request, fileType string) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "multipart body is required")
+		return
+	}
+	var targets []string
+	found := false
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FormName() != "filename" {
+			continue
+		}
+		found = true
+		scanner := bufio.NewScanner(part)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				targets = append(targets, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, err.Error())
+			return
+		}
+	}
+	if !found {
+		writeError(w, http.StatusBadRequest, jpush.CodeMissingParam, "filename is required")
+		return
+	}
+	s.mu.Lock()
+	f := &File{FileID: s.newID(), Type: fileType, Targets: targets, CreateTime: time.Now()}
+	s.files[f.FileID] = f
+	s.mu.Unlock()
+	writeJSON(w, jpush.FileUploadResponse{FileID: f.FileID})
+}
@@ -0,0 +1,116 @@
+package jpushtest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// Image uploaded image
+type Image struct {
+	MediaID   string
+	ImageType jpush.ImageType
+	URLs      *jpush.ImageURLRequest // 通过 url 上传时的请求
+	Filename  string                 // 通过文件上传时的文件名
+	Data      []byte                 // 通过文件上传时的文件内容
+}
+
+// Image get uploaded image by media id
+func (s *Server) Image(mediaID string) (Image, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	img, ok := s.images[mediaID]
+	if !ok {
+		return Image{}, false
+	}
+	return *img, true
+}
+
+// imageResponse convert image to api response
+func (s *Server) imageResponse(img *Image) jpush.ImageResponse {
+	ret := jpush.ImageResponse{MediaID: img.MediaID}
+	if img.URLs != nil {
+		ret.ImageURL = img.URLs.ImageURL
+		ret.XiaomiImageURL = img.URLs.XiaomiImageURL
+		ret.HuaweiImageURL = img.URLs.HuaweiImageURL
+		ret.HonorImageURL = img.URLs.HonorImageURL
+		ret.OppoImageURL = img.URLs.OppoImageURL
+		ret.FcmImageURL = img.URLs.FcmImageURL
+	} else {
+		ret.ImageURL = s.URL + "/images/" + img.MediaID
+	}
+	return ret
+}
+
+// handleImage handle images/byurls[/{media_id}] and images/byfiles[/{media_id}]
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request, path string) {
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 || (parts[0] != "byurls" && parts[0] != "byfiles") {
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+		return
+	}
+	var img *Image
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		img = &Image{}
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		s.mu.Lock()
+		old, ok := s.images[parts[1]]
+		if ok {
+			copied := *old
+			img = &copied
+		}
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "media "+parts[1]+" not exist")
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if parts[0] == "byurls" {
+		req := new(jpush.ImageURLRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		if req.ImageType != 0 {
+			img.ImageType = req.ImageType
+		}
+		img.URLs, img.Filename, img.Data = req, "", nil
+	} else {
+		if value := r.FormValue("image_type"); value != "" {
+			n, _ := strconv.Atoi(value)
+			img.ImageType = jpush.ImageType(n)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, jpush.CodeMissingParam, "file is required")
+			return
+		}
+		data, err := ioutil.ReadAll(file)
+		file.Close()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, err.Error())
+			return
+		}
+		img.URLs, img.Filename, img.Data = nil, header.Filename, data
+	}
+	if img.ImageType != jpush.ImageBigPicture && img.ImageType != jpush.ImageLargeIcon {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "invalid image_type")
+		return
+	}
+
+	s.mu.Lock()
+	if img.MediaID == "" {
+		img.MediaID = "jgmedia-" + strconv.Itoa(int(img.ImageType)) + "-" + s.newID()
+	}
+	s.images[img.MediaID] = img
+	ret := s.imageResponse(img)
+	s.mu.Unlock()
+	writeJSON(w, ret)
+}
@@ -0,0 +1,252 @@
+package jpushtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// handlePush handle push, push/validate, push/file and grouppush, the
+// repeated push of a cid returns the msg id of the first push
+func (s *Server) handlePush(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "only support POST method")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, err.Error())
+		return
+	}
+	req := new(jpush.PushRequest)
+	if err := json.Unmarshal(body, req); err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "invalid json: "+err.Error())
+		return
+	}
+	if req.Platform == nil || req.Audience == nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeMissingParam, "platform and audience are required")
+		return
+	}
+	if req.Notification == nil && req.Message == nil && req.LiveActivity == nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeMissingParam, "notification or message is required")
+		return
+	}
+	if req.LiveActivity != nil && (req.Notification != nil || req.Message != nil) {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "live_activity can not be combined with notification or message")
+		return
+	}
+
+	endpoint := EndpointPush
+	switch r.URL.Path {
+	case "/v3/push/validate":
+		endpoint = EndpointValidate
+	case "/v3/grouppush":
+		endpoint = EndpointGroupPush
+	case "/v3/push/file":
+		endpoint = EndpointFilePush
+		if !s.checkFileAudience(w, req) {
+			return
+		}
+	}
+	s.mu.Lock()
+	if msgID, ok := s.cids[req.Cid]; ok && req.Cid != "" {
+		// the push of the same cid is sent once, as JPush deduplicates it
+		s.mu.Unlock()
+		writeJSON(w, jpush.PushResponse{MsgID: msgID, Sendno: sendNo(req)})
+		return
+	}
+	msgID := s.newID()
+	if req.Cid != "" {
+		s.cids[req.Cid] = msgID
+	}
+	s.pushes = append(s.pushes, Push{
+		Endpoint: endpoint,
+		MsgID:    msgID,
+		Request:  req,
+		Body:     body,
+		Time:     time.Now(),
+	})
+	s.mu.Unlock()
+
+	writeJSON(w, jpush.PushResponse{MsgID: msgID, Sendno: sendNo(req)})
+}
+
+// sendNo get the sendno of the push response
+func sendNo(req *jpush.PushRequest) string {
+	if req.Options != nil {
+		return strconv.Itoa(req.Options.SendNo)
+	}
+	return "0"
+}
+
+// handleCid handle push/cid
+func (s *Server) handleCid(w http.ResponseWriter, r *http.Request, path string) {
+	count := 1
+	if value := r.URL.Query().Get("count"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 1000 {
+			writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "count must be in 1~1000")
+			return
+		}
+		count = n
+	}
+	ret := jpush.PushCIDResponse{}
+	s.mu.Lock()
+	for i := 0; i < count; i++ {
+		ret.Cids = append(ret.Cids, s.AppKey+"-"+s.newID())
+	}
+	s.mu.Unlock()
+	writeJSON(w, ret)
+}
+
+// handleBatch handle push/batch/regid/single and push/batch/alias/single,
+// each push of the batch is captured with the target as audience
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "only support POST method")
+		return
+	}
+	req := new(jpush.BatchPushRequest)
+	if !readJSON(w, r, req) {
+		return
+	}
+	if len(req.PushList) == 0 || len(req.PushList) > 1000 {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "pushlist size must be in 1~1000")
+		return
+	}
+	byAlias := r.URL.Path == "/v3/push/batch/alias/single"
+	cids := make([]string, 0, len(req.PushList))
+	for cid := range req.PushList {
+		cids = append(cids, cid)
+	}
+	sort.Strings(cids)
+
+	ret := make(map[string]jpush.BatchPushResult)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cid := range cids {
+		item := req.PushList[cid]
+		if item == nil || item.Target == "" || item.Platform == nil {
+			ret[cid] = jpush.BatchPushResult{Error: &jpush.ErrorMessage{Code: jpush.CodeMissingParam, Message: "platform and target are required"}}
+			continue
+		}
+		if item.Notification == nil && item.Message == nil {
+			ret[cid] = jpush.BatchPushResult{Error: &jpush.ErrorMessage{Code: jpush.CodeMissingParam, Message: "notification or message is required"}}
+			continue
+		}
+		if msgID, ok := s.cids[cid]; ok {
+			ret[cid] = jpush.BatchPushResult{MsgID: msgID}
+			continue
+		}
+		aud := &jpush.Audience{}
+		if byAlias {
+			aud.Alias = []string{item.Target}
+		} else {
+			aud.RegistrationID = []string{item.Target}
+		}
+		msgID := s.newID()
+		s.cids[cid] = msgID
+		s.pushes = append(s.pushes, Push{
+			Endpoint: EndpointBatch,
+			MsgID:    msgID,
+			Request: &jpush.PushRequest{
+				Cid:          cid,
+				Platform:     item.Platform,
+				Audience:     &jpush.PushAudience{Aud: aud},
+				Notification: item.Notification,
+				Message:      item.Message,
+				SmsMessage:   item.SmsMessage,
+				Options:      item.Options,
+				Callback:     item.Callback,
+			},
+			Time: time.Now(),
+		})
+		ret[cid] = jpush.BatchPushResult{MsgID: msgID}
+	}
+	writeJSON(w, ret)
+}
+
+// SendCallback POST the callback event of the push of msgID to its
+// callback url, as JPush does when the push is sent, received or clicked
+func (s *Server) SendCallback(msgID string, typ jpush.CallbackType, registrationID string) error {
+	s.mu.Lock()
+	var callback *jpush.Callback
+	for _, push := range s.pushes {
+		if push.MsgID == msgID && push.Request != nil {
+			callback = push.Request.Callback
+			break
+		}
+	}
+	s.mu.Unlock()
+	if callback == nil || callback.URL == "" {
+		return fmt.Errorf("jpushtest: push %s has no callback url", msgID)
+	}
+	buf, _ := json.Marshal([]map[string]interface{}{{
+		"type":            typ,
+		"appkey":          s.AppKey,
+		"msg_id":          msgID,
+		"registration_id": registrationID,
+		"platform":        "android",
+		"time":            time.Now().UnixNano() / int64(time.Millisecond),
+		"params":          callback.Params,
+	}})
+	resp, err := http.Post(callback.URL, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jpushtest: callback status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetDelivered mark the push of msgID as delivered, it can not be withdrawn
+func (s *Server) SetDelivered(msgID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered[msgID] = true
+}
+
+// Withdrawn check if the push of msgID is withdrawn
+func (s *Server) Withdrawn(msgID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.withdrawn[msgID]
+}
+
+// handleWithdraw handle DELETE push/{msg_id}
+func (s *Server) handleWithdraw(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodDelete || path == "" || strings.Contains(path, "/") {
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var push *Push
+	for i := range s.pushes {
+		if s.pushes[i].MsgID == path {
+			push = &s.pushes[i]
+			break
+		}
+	}
+	switch {
+	case push == nil:
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "msg_id "+path+" not exist")
+	case s.delivered[path]:
+		writeError(w, http.StatusBadRequest, jpush.CodeWithdrawDelivered, "message already delivered")
+	case time.Since(push.Time) > 24*time.Hour:
+		writeError(w, http.StatusBadRequest, jpush.CodeWithdrawTooOld, "only message in one day can be withdrawn")
+	default:
+		s.withdrawn[path] = true
+		writeJSON(w, jpush.DefaultResponse{})
+	}
+}
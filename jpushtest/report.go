@@ -0,0 +1,217 @@
+package jpushtest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// SetReceived set the received report of msgID
+func (s *Server) SetReceived(msgID string, stat jpush.ReportReceivedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received[msgID] = stat
+}
+
+// SetMessages set the messages report of msgID
+func (s *Server) SetMessages(msgID string, stat jpush.ReportMessagesResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[msgID] = stat
+}
+
+// SetReceivedDetail set the received detail report of msgID
+func (s *Server) SetReceivedDetail(msgID string, stat jpush.ReportReceivedDetailResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receivedDetail[msgID] = stat
+}
+
+// SetMessagesDetail set the messages detail report of msgID, also used as
+// the group messages detail report of the group msg id
+func (s *Server) SetMessagesDetail(msgID string, details jpush.ReportMessagesDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messagesDetail[msgID] = details
+}
+
+// msgIDs parse msg_ids query param, write error response on failure
+func msgIDs(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	return queryIDs(w, r, "msg_ids")
+}
+
+// queryIDs parse ids of the query param name, write error response on failure
+func queryIDs(w http.ResponseWriter, r *http.Request, name string) ([]string, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		writeError(w, http.StatusBadRequest, jpush.CodeReportInvalidMsgID, name+" is required")
+		return nil, false
+	}
+	ids := strings.Split(value, ",")
+	if len(ids) > 100 {
+		writeError(w, http.StatusBadRequest, jpush.CodeReportTooManyIDs, name+" is more than 100")
+		return nil, false
+	}
+	for _, id := range ids {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, jpush.CodeReportInvalidMsgID, "invalid msg_id "+id)
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// handleReport handle received, messages, status/message, users, the
+// detail reports and the group reports
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "received" && r.Method == http.MethodGet:
+		ids, ok := msgIDs(w, r)
+		if !ok {
+			return
+		}
+		ret := make([]jpush.ReportReceivedResponse, 0, len(ids))
+		s.mu.Lock()
+		for _, id := range ids {
+			stat := s.received[id]
+			stat.MsgID = jpush.MsgID(id)
+			ret = append(ret, stat)
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case path == "messages" && r.Method == http.MethodGet:
+		ids, ok := msgIDs(w, r)
+		if !ok {
+			return
+		}
+		ret := make([]jpush.ReportMessagesResponse, 0, len(ids))
+		s.mu.Lock()
+		for _, id := range ids {
+			stat := s.messages[id]
+			stat.MsgID = jpush.MsgID(id)
+			ret = append(ret, stat)
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case path == "status/message" && r.Method == http.MethodPost:
+		req := new(jpush.ReportStatusRequest)
+		if !readJSON(w, r, req) {
+			return
+		}
+		ret := make(map[string]jpush.MessageStatus)
+		s.mu.Lock()
+		for _, id := range req.RegistrationIds {
+			if _, ok := s.devices[id]; ok {
+				ret[id] = jpush.MessageStatus{Status: 0}
+			} else {
+				ret[id] = jpush.MessageStatus{Status: 2}
+			}
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case path == "received/detail" && r.Method == http.MethodGet:
+		ids, ok := msgIDs(w, r)
+		if !ok {
+			return
+		}
+		ret := make([]jpush.ReportReceivedDetailResponse, 0, len(ids))
+		s.mu.Lock()
+		for _, id := range ids {
+			stat := s.receivedDetail[id]
+			stat.MsgID = jpush.MsgID(id)
+			ret = append(ret, stat)
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case path == "messages/detail" && r.Method == http.MethodGet:
+		ids, ok := msgIDs(w, r)
+		if !ok {
+			return
+		}
+		ret := make([]jpush.ReportMessagesDetailResponse, 0, len(ids))
+		s.mu.Lock()
+		for _, id := range ids {
+			details := s.messagesDetail[id]
+			ret = append(ret, jpush.ReportMessagesDetailResponse{MsgID: jpush.MsgID(id), Details: &details})
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case path == "group/messages/detail" && r.Method == http.MethodGet:
+		ids, ok := queryIDs(w, r, "group_msgids")
+		if !ok {
+			return
+		}
+		ret := make([]jpush.GroupReportMessagesDetailResponse, 0, len(ids))
+		s.mu.Lock()
+		for _, id := range ids {
+			details := s.messagesDetail[id]
+			ret = append(ret, jpush.GroupReportMessagesDetailResponse{GroupMsgID: jpush.MsgID(id), Details: &details})
+		}
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case (path == "users" || path == "group/users") && r.Method == http.MethodGet:
+		s.handleUsers(w, r)
+	default:
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+	}
+}
+
+// reportUser users report item with raw time
+type reportUser struct {
+	Time    string                   `json:"time"`
+	Android *jpush.ReportUserAndroid `json:"android,omitempty"`
+	IOS     *jpush.ReportUserIOS     `json:"ios,omitempty"`
+}
+
+// handleUsers handle users, return empty stat of each time unit, the hour
+// stats are only of the day of start as JPush does
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var layout string
+	var limit int
+	var next func(time.Time) time.Time
+	switch q.Get("time_unit") {
+	case "HOUR":
+		layout, limit = "2006-01-02 15", 24
+		next = func(t time.Time) time.Time { return t.Add(time.Hour) }
+	case "DAY":
+		layout, limit = "2006-01-02", 60
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "MONTH":
+		layout, limit = "2006-01", 2
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "invalid time_unit")
+		return
+	}
+	start, err := time.Parse(layout, q.Get("start"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "invalid start")
+		return
+	}
+	duration, err := strconv.Atoi(q.Get("duration"))
+	if err != nil || duration < 1 || duration > limit {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "invalid duration")
+		return
+	}
+	items := make([]reportUser, 0, duration)
+	for t, i := start, 0; i < duration; t, i = next(t), i+1 {
+		if q.Get("time_unit") == "HOUR" && t.Day() != start.Day() {
+			break
+		}
+		items = append(items, reportUser{
+			Time:    t.Format(layout),
+			Android: &jpush.ReportUserAndroid{},
+			IOS:     &jpush.ReportUserIOS{},
+		})
+	}
+	writeJSON(w, map[string]interface{}{
+		"time_unit": q.Get("time_unit"),
+		"start":     q.Get("start"),
+		"duration":  duration,
+		"items":     items,
+	})
+}
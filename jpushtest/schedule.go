@@ -0,0 +1,145 @@
+package jpushtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// schedulePageSize schedule count of each page
+const schedulePageSize = 50
+
+// Schedules get all schedules
+func (s *Server) Schedules() []jpush.ScheduleResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]jpush.ScheduleResponse, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		ret = append(ret, *schedule)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ScheduleID < ret[j].ScheduleID })
+	return ret
+}
+
+// readSchedule decode schedule request, also return the keys present in body
+func readSchedule(w http.ResponseWriter, r *http.Request) (*jpush.ScheduleRequest, map[string]json.RawMessage, bool) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeScheduleInvalid, err.Error())
+		return nil, nil, false
+	}
+	req := new(jpush.ScheduleRequest)
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, req); err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeScheduleInvalid, "invalid json: "+err.Error())
+		return nil, nil, false
+	}
+	json.Unmarshal(body, &fields)
+	return req, fields, true
+}
+
+// handleSchedule handle schedules, schedules/{schedule_id} and schedules/{schedule_id}/msg_ids
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request, path string) {
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "" && r.Method == http.MethodPost:
+		req, _, ok := readSchedule(w, r)
+		if !ok {
+			return
+		}
+		if req.Name == "" || req.Trigger == nil || req.Push == nil {
+			writeError(w, http.StatusBadRequest, jpush.CodeScheduleInvalid, "name, trigger and push are required")
+			return
+		}
+		s.mu.Lock()
+		schedule := &jpush.ScheduleResponse{
+			ScheduleID: s.newID(),
+			Name:       req.Name,
+			Enabled:    req.Enabled,
+			Trigger:    req.Trigger,
+			Push:       req.Push,
+		}
+		s.schedules[schedule.ScheduleID] = schedule
+		ret := *schedule
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case path == "" && r.Method == http.MethodGet:
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		all := s.Schedules()
+		ret := jpush.SchedulePageResponse{
+			TotalCount: len(all),
+			TotalPages: (len(all) + schedulePageSize - 1) / schedulePageSize,
+			Page:       page,
+			Schedules:  []jpush.ScheduleResponse{},
+		}
+		if start := (page - 1) * schedulePageSize; start < len(all) {
+			end := start + schedulePageSize
+			if end > len(all) {
+				end = len(all)
+			}
+			ret.Schedules = all[start:end]
+		}
+		writeJSON(w, ret)
+	case len(parts) == 1 || (len(parts) == 2 && parts[1] == "msg_ids" && r.Method == http.MethodGet):
+		s.handleScheduleID(w, r, parts[0], len(parts) == 2)
+	default:
+		writeError(w, http.StatusNotFound, jpush.CodeScheduleInvalid, "api not found")
+	}
+}
+
+// handleScheduleID handle the schedule with id
+func (s *Server) handleScheduleID(w http.ResponseWriter, r *http.Request, id string, msgIDs bool) {
+	s.mu.Lock()
+	_, ok := s.schedules[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, jpush.CodeScheduleInvalid, "schedule "+id+" not exist")
+		return
+	}
+	switch {
+	case msgIDs:
+		writeJSON(w, jpush.ScheduleMsgsResponse{MsgIDs: []interface{}{}})
+	case r.Method == http.MethodGet:
+		s.mu.Lock()
+		ret := *s.schedules[id]
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case r.Method == http.MethodPut:
+		req, fields, ok := readSchedule(w, r)
+		if !ok {
+			return
+		}
+		s.mu.Lock()
+		schedule := s.schedules[id]
+		if req.Name != "" {
+			schedule.Name = req.Name
+		}
+		if _, ok := fields["enabled"]; ok {
+			schedule.Enabled = req.Enabled
+		}
+		if req.Trigger != nil {
+			schedule.Trigger = req.Trigger
+		}
+		if req.Push != nil {
+			schedule.Push = req.Push
+		}
+		ret := *schedule
+		s.mu.Unlock()
+		writeJSON(w, ret)
+	case r.Method == http.MethodDelete:
+		s.mu.Lock()
+		delete(s.schedules, id)
+		s.mu.Unlock()
+		writeJSON(w, jpush.DefaultResponse{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, jpush.CodeMethodNotAllowed, "method not allowed")
+	}
+}
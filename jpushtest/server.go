@@ -0,0 +1,365 @@
+// Package jpushtest provides an in-process fake JPush server for tests.
+//
+// The server keeps devices, tags, aliases and schedules in memory, checks the
+// Basic authorization of each request, captures the pushes it receives and can
+// inject errors and rate limit headers:
+//
+//	srv := jpushtest.NewServer("appkey", "secret")
+//	defer srv.Close()
+//	client := srv.JPushClient()
+//	client.Push(req)
+//	pushes := srv.Pushes()
+package jpushtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+// endpoint names used by InjectError
+const (
+	EndpointPush      = "push"
+	EndpointGroupPush = "grouppush"
+	EndpointValidate  = "validate"
+	EndpointCid       = "cid"
+	EndpointBatch     = "batch"
+	EndpointWithdraw  = "withdraw"
+	EndpointFile      = "file"
+	EndpointFilePush  = "filepush"
+	EndpointImage     = "image"
+	EndpointDevice    = "device"
+	EndpointAlias     = "alias"
+	EndpointTag       = "tag"
+	EndpointReport    = "report"
+	EndpointSchedule  = "schedule"
+	EndpointAdmin     = "admin"
+)
+
+// Fault define an error injected into the responses of an endpoint
+type Fault struct {
+	Status  int    // HTTP 状态码
+	Code    int    // JPush 错误码，0 时响应内容为 Body
+	Message string // JPush 错误信息
+	Body    string // Code 为 0 时的原始响应内容，如网关返回的 HTML
+	Times   int    // 生效次数，0 表示一直生效
+}
+
+// Push captured push request
+type Push struct {
+	Endpoint string // EndpointPush、EndpointGroupPush、EndpointValidate、EndpointBatch 或 EndpointFilePush
+	MsgID    string
+	Request  *jpush.PushRequest
+	Body     []byte
+	Time     time.Time
+}
+
+// Device fake device state
+type Device struct {
+	RegistrationID string
+	Platform       string
+	Alias          string
+	Mobile         string
+	Tags           []string
+	Online         bool
+}
+
+// Server fake JPush server
+type Server struct {
+	*httptest.Server
+	AppKey       string
+	MasterSecret string
+
+	mu             sync.Mutex
+	nextID         int64
+	pushes         []Push
+	devices        map[string]*Device
+	schedules      map[string]*jpush.ScheduleResponse
+	received       map[string]jpush.ReportReceivedResponse
+	messages       map[string]jpush.ReportMessagesResponse
+	cids           map[string]string // cid 对应的 msg_id
+	receivedDetail map[string]jpush.ReportReceivedDetailResponse
+	messagesDetail map[string]jpush.ReportMessagesDetails
+	faults         map[string]*Fault
+	delivered      map[string]bool
+	withdrawn      map[string]bool
+	files          map[string]*File
+	images         map[string]*Image
+
+	quota       int
+	remaining   int
+	reset       time.Duration
+	windowStart time.Time
+}
+
+// NewServer start a fake server accepting appKey and masterSecret,
+// the server has a default rate limit of 600 calls per minute
+func NewServer(appKey, masterSecret string) *Server {
+	s := &Server{
+		AppKey:         appKey,
+		MasterSecret:   masterSecret,
+		nextID:         1000000000,
+		devices:        make(map[string]*Device),
+		schedules:      make(map[string]*jpush.ScheduleResponse),
+		received:       make(map[string]jpush.ReportReceivedResponse),
+		messages:       make(map[string]jpush.ReportMessagesResponse),
+		cids:           make(map[string]string),
+		receivedDetail: make(map[string]jpush.ReportReceivedDetailResponse),
+		messagesDetail: make(map[string]jpush.ReportMessagesDetails),
+		faults:         make(map[string]*Fault),
+		delivered:      make(map[string]bool),
+		withdrawn:      make(map[string]bool),
+		files:          make(map[string]*File),
+		images:         make(map[string]*Image),
+		quota:          600,
+		remaining:      600,
+		reset:          time.Minute,
+		windowStart:    time.Now(),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URLs get the api urls of the server, use with jpush.WithBaseURLs
+func (s *Server) URLs() map[string]string {
+	return map[string]string{
+		"push":     s.URL + "/v3/",
+		"report":   s.URL + "/v3/report/",
+		"device":   s.URL + "/v3/devices/",
+		"alias":    s.URL + "/v3/aliases/",
+		"tag":      s.URL + "/v3/tags/",
+		"schedule": s.URL + "/v3/schedules/",
+		"admin":    s.URL + "/v1/",
+	}
+}
+
+// JPushClient new a JPush client of the server, the embedded
+// httptest.Server.Client still returns the *http.Client of the server
+func (s *Server) JPushClient(opts ...jpush.Option) *jpush.JPush {
+	opts = append([]jpush.Option{jpush.WithBaseURLs(s.URLs())}, opts...)
+	return jpush.NewJPush(s.AppKey, s.MasterSecret, opts...)
+}
+
+// GroupClient new a GroupPush client of the server
+func (s *Server) GroupClient(opts ...jpush.Option) *jpush.GroupPush {
+	opts = append([]jpush.Option{jpush.WithBaseURLs(s.URLs())}, opts...)
+	return jpush.NewGroupPush(s.AppKey, s.MasterSecret, opts...)
+}
+
+// Pushes get all captured push requests
+func (s *Server) Pushes() []Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pushes := make([]Push, len(s.pushes))
+	copy(pushes, s.pushes)
+	return pushes
+}
+
+// LastPush get the last captured push request
+func (s *Server) LastPush() (Push, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pushes) == 0 {
+		return Push{}, false
+	}
+	return s.pushes[len(s.pushes)-1], true
+}
+
+// Reset clear captured pushes, devices, files, images, schedules, reports and faults
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushes = nil
+	s.devices = make(map[string]*Device)
+	s.schedules = make(map[string]*jpush.ScheduleResponse)
+	s.received = make(map[string]jpush.ReportReceivedResponse)
+	s.messages = make(map[string]jpush.ReportMessagesResponse)
+	s.cids = make(map[string]string)
+	s.receivedDetail = make(map[string]jpush.ReportReceivedDetailResponse)
+	s.messagesDetail = make(map[string]jpush.ReportMessagesDetails)
+	s.faults = make(map[string]*Fault)
+	s.delivered = make(map[string]bool)
+	s.withdrawn = make(map[string]bool)
+	s.files = make(map[string]*File)
+	s.images = make(map[string]*Image)
+}
+
+// InjectError make the requests of endpoint fail with fault
+func (s *Server) InjectError(endpoint string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = &fault
+}
+
+// ClearErrors remove all injected errors
+func (s *Server) ClearErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[string]*Fault)
+}
+
+// SetRateLimit set the rate limit window, requests over quota get 429
+func (s *Server) SetRateLimit(quota, remaining int, reset time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quota = quota
+	s.remaining = remaining
+	s.reset = reset
+	s.windowStart = time.Now()
+}
+
+// newID generate a numeric id, must be called with lock
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.FormatInt(s.nextID, 10)
+}
+
+// serveHTTP route request to endpoint handler
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	var endpoint string
+	var handler func(http.ResponseWriter, *http.Request, string)
+	switch {
+	case path == "/v3/push":
+		endpoint, handler = EndpointPush, s.handlePush
+	case path == "/v3/push/validate":
+		endpoint, handler = EndpointValidate, s.handlePush
+	case path == "/v3/grouppush":
+		endpoint, handler = EndpointGroupPush, s.handlePush
+	case path == "/v3/push/cid":
+		endpoint, handler = EndpointCid, s.handleCid
+	case path == "/v3/push/batch/regid/single" || path == "/v3/push/batch/alias/single":
+		endpoint, handler = EndpointBatch, s.handleBatch
+	case path == "/v3/push/file":
+		endpoint, handler = EndpointFilePush, s.handlePush
+	case path == "/v3/files" || strings.HasPrefix(path, "/v3/files/"):
+		endpoint, handler = EndpointFile, s.handleFile
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "/v3/files"), "/")
+	case strings.HasPrefix(path, "/v3/images/"):
+		endpoint, handler = EndpointImage, s.handleImage
+		path = strings.TrimPrefix(path, "/v3/images/")
+	case strings.HasPrefix(path, "/v3/push/"):
+		endpoint, handler = EndpointWithdraw, s.handleWithdraw
+		path = strings.TrimPrefix(path, "/v3/push/")
+	case strings.HasPrefix(path, "/v3/devices/"):
+		endpoint, handler = EndpointDevice, s.handleDevice
+		path = strings.TrimPrefix(path, "/v3/devices/")
+	case strings.HasPrefix(path, "/v3/aliases/"):
+		endpoint, handler = EndpointAlias, s.handleAlias
+		path = strings.TrimPrefix(path, "/v3/aliases/")
+	case strings.HasPrefix(path, "/v3/tags/"):
+		endpoint, handler = EndpointTag, s.handleTag
+		path = strings.TrimPrefix(path, "/v3/tags/")
+	case strings.HasPrefix(path, "/v3/report/"):
+		endpoint, handler = EndpointReport, s.handleReport
+		path = strings.TrimPrefix(path, "/v3/report/")
+	case strings.HasPrefix(path, "/v3/schedules/"):
+		endpoint, handler = EndpointSchedule, s.handleSchedule
+		path = strings.TrimPrefix(path, "/v3/schedules/")
+	case strings.HasPrefix(path, "/v1/"):
+		endpoint, handler = EndpointAdmin, s.handleAdmin
+		path = strings.TrimPrefix(path, "/v1/")
+	default:
+		writeError(w, http.StatusNotFound, jpush.CodeInvalidParam, "api not found")
+		return
+	}
+
+	group := endpoint == EndpointGroupPush || (endpoint == EndpointReport && strings.HasPrefix(path, "group/"))
+	if !s.checkAuth(r, group) {
+		writeError(w, http.StatusUnauthorized, jpush.CodeAuthFailed, "Basic authentication failed")
+		return
+	}
+	if !s.takeQuota(w) {
+		writeError(w, http.StatusTooManyRequests, jpush.CodeRateLimited, "Request times is exceeded the limit")
+		return
+	}
+	if s.writeFault(w, endpoint) {
+		return
+	}
+	handler(w, r, path)
+}
+
+// checkAuth check the Basic authorization, group request use "group-" prefix
+func (s *Server) checkAuth(r *http.Request, group bool) bool {
+	key := s.AppKey
+	if group {
+		key = "group-" + key
+	}
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(key+":"+s.MasterSecret))
+	return r.Header.Get("Authorization") == auth
+}
+
+// takeQuota take one call from rate limit window and write rate limit headers
+func (s *Server) takeQuota(w http.ResponseWriter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.windowStart) >= s.reset {
+		s.windowStart = now
+		s.remaining = s.quota
+	}
+	ok := s.remaining > 0
+	if ok {
+		s.remaining--
+	}
+	reset := s.windowStart.Add(s.reset).Sub(now)
+	w.Header().Set("X-Rate-Limit-Quota", strconv.Itoa(s.quota))
+	w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(s.remaining))
+	w.Header().Set("X-Rate-Limit-Reset", strconv.Itoa(int((reset+time.Second-1)/time.Second)))
+	return ok
+}
+
+// writeFault write the injected error of endpoint if any
+func (s *Server) writeFault(w http.ResponseWriter, endpoint string) bool {
+	s.mu.Lock()
+	fault, ok := s.faults[endpoint]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	f := *fault
+	if fault.Times > 0 {
+		fault.Times--
+		if fault.Times == 0 {
+			delete(s.faults, endpoint)
+		}
+	}
+	s.mu.Unlock()
+
+	if f.Code == 0 {
+		w.WriteHeader(f.Status)
+		w.Write([]byte(f.Body))
+		return true
+	}
+	writeError(w, f.Status, f.Code, f.Message)
+	return true
+}
+
+// writeJSON write v as json response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError write JPush error response
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(jpush.ErrorResponse{Error: jpush.ErrorMessage{Code: code, Message: message}})
+}
+
+// readJSON decode the request body into v, write error response on failure
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, jpush.CodeInvalidParam, "invalid json: "+err.Error())
+		return false
+	}
+	return true
+}
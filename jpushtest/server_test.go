@@ -0,0 +1,127 @@
+package jpushtest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+func newRequest(t *testing.T) *jpush.PushRequest {
+	req, err := jpush.NewPush().Android().ToAll().Alert("hello").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestAuth(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	req := newRequest(t)
+
+	if _, err := srv.JPushClient().Push(req); err != nil {
+		t.Errorf("push: %v", err)
+	}
+	if _, err := srv.GroupClient().GroupPush(req); err != nil {
+		t.Errorf("group push: %v", err)
+	}
+
+	wrong := jpush.NewJPush("appkey", "wrong", jpush.WithBaseURLs(srv.URLs()))
+	if _, err := wrong.Push(req); !jpush.IsAuthFailure(err) {
+		t.Errorf("wrong secret: got %v, want auth failure", err)
+	}
+	// grouppush requires the group- prefix, push rejects it
+	if _, err := srv.GroupClient().Push(req); !jpush.IsAuthFailure(err) {
+		t.Errorf("push with group auth: got %v, want auth failure", err)
+	}
+	if _, err := srv.GroupClient().GroupReportUsers(jpush.TimeUnitDay, time.Now(), 1); err != nil {
+		t.Errorf("group report: %v", err)
+	}
+	if len(srv.Pushes()) != 2 {
+		t.Errorf("got %d pushes, want 2", len(srv.Pushes()))
+	}
+}
+
+func TestInjectError(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	client := srv.JPushClient()
+	req := newRequest(t)
+
+	srv.InjectError(jpushtest.EndpointPush, jpushtest.Fault{Status: 400, Code: jpush.CodeInvalidParam, Message: "invalid", Times: 1})
+	_, err := client.Push(req)
+	var apiErr *jpush.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 || apiErr.Code != jpush.CodeInvalidParam {
+		t.Fatalf("got %v, want APIError 400 %d", err, jpush.CodeInvalidParam)
+	}
+	if _, err := client.Push(req); err != nil {
+		t.Errorf("push after the fault: %v", err)
+	}
+
+	srv.InjectError(jpushtest.EndpointPush, jpushtest.Fault{Status: 502, Body: "<html>bad gateway</html>"})
+	_, err = client.Push(req)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 502 || !jpush.IsRetryable(err) {
+		t.Errorf("got %v, want retryable 502", err)
+	}
+	srv.ClearErrors()
+	if _, err := client.Push(req); err != nil {
+		t.Errorf("push after clear: %v", err)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetRateLimit(10, 2, time.Minute)
+	client := srv.JPushClient()
+	req := newRequest(t)
+
+	if _, err := client.Push(req); err != nil {
+		t.Fatal(err)
+	}
+	limit := client.RateLimit()
+	if limit.Quota != 10 || limit.Remaining != 1 || limit.Reset <= 0 || limit.Reset > 60 {
+		t.Errorf("got rate limit %+v, want quota 10 remaining 1", limit)
+	}
+	if _, err := client.Push(req); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.Push(req)
+	if !jpush.IsRateLimited(err) {
+		t.Errorf("got %v, want rate limited", err)
+	}
+	var apiErr *jpush.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 429 || apiErr.RateLimit.Remaining != 0 {
+		t.Errorf("got %+v, want 429 with remaining 0", apiErr)
+	}
+}
+
+func TestCidDeduplicate(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	client := srv.JPushClient()
+
+	cids, err := client.PushGetCid(1, "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := newRequest(t)
+	req.Cid = cids.Cids[0]
+	first, err := client.Push(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.Push(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.MsgID != second.MsgID {
+		t.Errorf("got msg ids %s and %s, want the same", first.MsgID, second.MsgID)
+	}
+	if n := len(srv.Pushes()); n != 1 {
+		t.Errorf("got %d pushes, want 1", n)
+	}
+}
@@ -0,0 +1,73 @@
+package jpush
+
+// LiveActivityEvent define ios live activity event
+type LiveActivityEvent string
+
+// ios live activity events
+const (
+	LiveActivityStart  LiveActivityEvent = "start"
+	LiveActivityUpdate LiveActivityEvent = "update"
+	LiveActivityEnd    LiveActivityEvent = "end"
+)
+
+// LiveActivityAlert define ios live activity alert
+type LiveActivityAlert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	Sound string `json:"sound,omitempty"`
+}
+
+// LiveActivityIOS define ios live activity payload
+type LiveActivityIOS struct {
+	Event          LiveActivityEvent      `json:"event"`
+	ContentState   map[string]interface{} `json:"content-state,omitempty"`   // 与 ActivityAttributes.ContentState 对应
+	AttributesType string                 `json:"attributes-type,omitempty"` // start 事件必填，ActivityAttributes 类型名
+	Attributes     map[string]interface{} `json:"attributes,omitempty"`      // start 事件的静态属性
+	Alert          *LiveActivityAlert     `json:"alert,omitempty"`
+	DismissalDate  int64                  `json:"dismissal-date,omitempty"` // end 事件后移除的时间，unix 秒
+	StaleDate      int64                  `json:"stale-date,omitempty"`     // 过期时间，unix 秒
+	RelevanceScore float64                `json:"relevance-score,omitempty"`
+}
+
+// LiveActivity define live activity section of push request, it can not
+// be combined with notification or message
+type LiveActivity struct {
+	IOS *LiveActivityIOS `json:"ios"`
+}
+
+// checkLiveActivity check the live activity event and required fields
+func (r *PushRequest) checkLiveActivity(add func(field, format string, args ...interface{})) {
+	if r.Notification != nil || r.Message != nil {
+		add("live_activity", "can not be combined with notification or message")
+	}
+	if a := r.Audience; a != nil && (a.isAll || a.Aud == nil || a.Aud.LiveActivityID == "") {
+		add("audience.live_activity_id", "required by live activity")
+	}
+	if r.Platform != nil && (r.Platform.isAll || len(r.Platform.Platforms) != 1 || !r.Platform.has(PlatformIOS)) {
+		add("platform", "live activity only support ios")
+	}
+	ios := r.LiveActivity.IOS
+	if ios == nil {
+		add("live_activity.ios", "required")
+		return
+	}
+	switch ios.Event {
+	case LiveActivityStart:
+		if ios.AttributesType == "" {
+			add("live_activity.ios.attributes-type", "required by start event")
+		}
+		if ios.ContentState == nil {
+			add("live_activity.ios.content-state", "required by start event")
+		}
+	case LiveActivityUpdate:
+		if ios.ContentState == nil {
+			add("live_activity.ios.content-state", "required by update event")
+		}
+	case LiveActivityEnd:
+	default:
+		add("live_activity.ios.event", "unknown event %q", ios.Event)
+	}
+	if ios.RelevanceScore < 0 || ios.RelevanceScore > 1 {
+		add("live_activity.ios.relevance-score", "%v out of range 0~1", ios.RelevanceScore)
+	}
+}
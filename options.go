@@ -0,0 +1,77 @@
+package jpush
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configure the JPush client
+type Option func(*JPush)
+
+// WithHTTPClient use client to send api requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(j *JPush) {
+		if client != nil {
+			j.client = client
+		}
+	}
+}
+
+// WithTimeout set the timeout of each http request
+func WithTimeout(timeout time.Duration) Option {
+	return func(j *JPush) {
+		client := *j.client
+		client.Timeout = timeout
+		j.client = &client
+	}
+}
+
+// WithTransport set the transport of the http client
+func WithTransport(transport http.RoundTripper) Option {
+	return func(j *JPush) {
+		client := *j.client
+		client.Transport = transport
+		j.client = &client
+	}
+}
+
+// WithZone set jpush zone, unknown zone is ignored
+func WithZone(zone string) Option {
+	return func(j *JPush) {
+		j.SetZone(zone)
+	}
+}
+
+// WithBaseURLs override api urls of the zone for this client, keys are the
+// same as ZONES, e.g. "push", "report", "device"
+func WithBaseURLs(urls map[string]string) Option {
+	return func(j *JPush) {
+		if j.urls == nil {
+			j.urls = make(map[string]string, len(urls))
+		}
+		for key, url := range urls {
+			j.urls[key] = url
+		}
+	}
+}
+
+// WithUserAgent set the User-Agent header of api requests
+func WithUserAgent(userAgent string) Option {
+	return func(j *JPush) {
+		j.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy set the retry policy
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(j *JPush) {
+		j.SetRetryPolicy(policy)
+	}
+}
+
+// WithThrottle set client side throttle mode
+func WithThrottle(mode ThrottleMode) Option {
+	return func(j *JPush) {
+		j.SetThrottle(mode)
+	}
+}
@@ -2,10 +2,22 @@ package jpush
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strconv"
 )
 
+// platform names
+const (
+	PlatformAndroid  = "android"
+	PlatformIOS      = "ios"
+	PlatformHMOS     = "hmos"
+	PlatformQuickApp = "quickapp"
+
+	// Deprecated: winphone push is no longer supported by JPush
+	PlatformWinPhone = "winphone"
+)
+
 // Platform define platform entry
 type Platform struct {
 	isAll     bool
@@ -19,7 +31,7 @@ func (p *Platform) SetAll(all bool) {
 
 // UnmarshalJSON unmarshal json
 func (p *Platform) UnmarshalJSON(data []byte) error {
-	if string(data) == "all" {
+	if string(data) == `"all"` {
 		p.isAll = true
 		return nil
 	}
@@ -37,13 +49,15 @@ func (p *Platform) MarshalJSON() (data []byte, err error) {
 
 // Audience define Audience
 type Audience struct {
-	Tag            []string `json:"tag,omitempty"`
-	TagAnd         []string `json:"tag_and,omitempty"`
-	TagNot         []string `json:"tag_not,omitempty"`
-	Alias          []string `json:"alias,omitempty"`
-	RegistrationID []string `json:"registration_id,omitempty"`
-	Segment        []string `json:"segment,omitempty"`
-	ABTest         []string `json:"abtest,omitempty"`
+	Tag            []string      `json:"tag,omitempty"`
+	TagAnd         []string      `json:"tag_and,omitempty"`
+	TagNot         []string      `json:"tag_not,omitempty"`
+	Alias          []string      `json:"alias,omitempty"`
+	RegistrationID []string      `json:"registration_id,omitempty"`
+	Segment        []string      `json:"segment,omitempty"`
+	ABTest         []string      `json:"abtest,omitempty"`
+	File           *AudienceFile `json:"file,omitempty"`
+	LiveActivityID string        `json:"live_activity_id,omitempty"` // 仅用于 LiveActivity 推送
 }
 
 // PushAudience define audience entry
@@ -59,11 +73,14 @@ func (p *PushAudience) SetAll(all bool) {
 
 // UnmarshalJSON unmarshal json
 func (p *PushAudience) UnmarshalJSON(data []byte) error {
-	if string(data) == "all" {
+	if string(data) == `"all"` {
 		p.isAll = true
 		return nil
 	}
 	p.isAll = false
+	if p.Aud == nil {
+		p.Aud = &Audience{}
+	}
 	return json.Unmarshal(data, p.Aud)
 }
 
@@ -80,38 +97,211 @@ type PushNotification struct {
 	Alert    string                `json:"alert,omitempty"`
 	Android  *NotificationAndroid  `json:"android,omitempty"`
 	IOS      *NotificationIOS      `json:"ios,omitempty"`
+	HMOS     *NotificationHMOS     `json:"hmos,omitempty"`
+	QuickApp *NotificationQuickApp `json:"quickapp,omitempty"`
+	// Deprecated: winphone push is no longer supported by JPush
 	WinPhone *NotificationWinPhone `json:"winphone,omitempty"`
 }
 
+// AndroidStyle define android notification style
+type AndroidStyle int
+
+// android notification styles
+const (
+	StyleDefault    AndroidStyle = 0 // 默认样式
+	StyleBigText    AndroidStyle = 1 // 大文本，使用 BigText
+	StyleInbox      AndroidStyle = 2 // 文本条目，使用 Inbox
+	StyleBigPicture AndroidStyle = 3 // 大图片，使用 BigPicPath
+)
+
+// AndroidAlertType define android notification alert type, combine the
+// flags with |, the zero value is omitted and means AlertTypeAll
+type AndroidAlertType int
+
+// android notification alert types
+const (
+	AlertTypeAll     AndroidAlertType = -1 // 声音、振动、呼吸灯
+	AlertTypeSound   AndroidAlertType = 1  // 声音
+	AlertTypeVibrate AndroidAlertType = 2  // 振动
+	AlertTypeLights  AndroidAlertType = 4  // 呼吸灯
+)
+
+// AndroidPriority define android notification priority
+type AndroidPriority int
+
+// android notification priorities
+const (
+	PriorityMin     AndroidPriority = -2
+	PriorityLow     AndroidPriority = -1
+	PriorityDefault AndroidPriority = 0
+	PriorityHigh    AndroidPriority = 1
+	PriorityMax     AndroidPriority = 2
+)
+
 // NotificationAndroid define android notification
 type NotificationAndroid struct {
-	Alert      string                 `json:"alert"`
-	Title      string                 `json:"title,omitempty"`
-	BuilderID  int                    `json:"builder_id,int,omitempty"`
-	Priority   int                    `json:"priority,omitempty"`
-	Category   string                 `json:"category,omitempty"`
-	Style      int                    `json:"style,int,omitempty"`
-	AlertType  int                    `json:"alert_type,int,omitempty"`
-	BigText    string                 `json:"big_text,omitempty"`
-	Inbox      map[string]interface{} `json:"inbox,omitempty"`
-	BigPicPath string                 `json:"big_pic_path,omitempty"`
-	Extras     map[string]interface{} `json:"extras,omitempty"`
-	LargeIcon  string                 `json:"large_icon,omitempty"`
-	Intent     map[string]interface{} `json:"intent,omitempty"`
+	Alert             string                 `json:"alert"`
+	Title             string                 `json:"title,omitempty"`
+	BuilderID         int                    `json:"builder_id,int,omitempty"`
+	ChannelID         string                 `json:"channel_id,omitempty"`
+	Priority          AndroidPriority        `json:"priority,omitempty"`
+	Category          string                 `json:"category,omitempty"`
+	Style             AndroidStyle           `json:"style,int,omitempty"`
+	AlertType         AndroidAlertType       `json:"alert_type,int,omitempty"`
+	BigText           string                 `json:"big_text,omitempty"`
+	Inbox             map[string]interface{} `json:"inbox,omitempty"`
+	BigPicPath        string                 `json:"big_pic_path,omitempty"` // 图片地址或 ImageUploadByURL 返回的 media_id
+	Extras            map[string]interface{} `json:"extras,omitempty"`
+	LargeIcon         string                 `json:"large_icon,omitempty"` // 图标地址或 ImageUploadByURL 返回的 media_id
+	SmallIconURI      string                 `json:"small_icon_uri,omitempty"`
+	Intent            map[string]interface{} `json:"intent,omitempty"`
+	URIActivity       string                 `json:"uri_activity,omitempty"`
+	URIAction         string                 `json:"uri_action,omitempty"`
+	BadgeAddNum       int                    `json:"badge_add_num,omitempty"`
+	BadgeSetNum       int                    `json:"badge_set_num,omitempty"`
+	BadgeClass        string                 `json:"badge_class,omitempty"`
+	Sound             string                 `json:"sound,omitempty"`
+	ShowBeginTime     string                 `json:"show_begin_time,omitempty"`    // 格式 yyyy-MM-dd HH:mm:ss
+	ShowEndTime       string                 `json:"show_end_time,omitempty"`      // 格式 yyyy-MM-dd HH:mm:ss
+	DisplayForeground string                 `json:"display_foreground,omitempty"` // "1" 前台展示，"0" 前台不展示
+}
+
+// IOSAlert define apns alert dictionary
+type IOSAlert struct {
+	Title           string   `json:"title,omitempty"`
+	Subtitle        string   `json:"subtitle,omitempty"`
+	Body            string   `json:"body,omitempty"`
+	TitleLocKey     string   `json:"title-loc-key,omitempty"`
+	TitleLocArgs    []string `json:"title-loc-args,omitempty"`
+	SubtitleLocKey  string   `json:"subtitle-loc-key,omitempty"`
+	SubtitleLocArgs []string `json:"subtitle-loc-args,omitempty"`
+	LocKey          string   `json:"loc-key,omitempty"`
+	LocArgs         []string `json:"loc-args,omitempty"`
+	ActionLocKey    string   `json:"action-loc-key,omitempty"`
+	LaunchImage     string   `json:"launch-image,omitempty"`
+}
+
+// IOSSound define apns critical alert sound dictionary
+type IOSSound struct {
+	Critical int     `json:"critical"` // 1 为 critical alert
+	Name     string  `json:"name"`
+	Volume   float64 `json:"volume"` // 0.0~1.0
+}
+
+// IOSBadge define ios badge, a number sets the badge and "+N" or "-N"
+// changes it based on the current badge
+type IOSBadge string
+
+// BadgeNumber get badge of number n
+func BadgeNumber(n int) IOSBadge {
+	return IOSBadge(strconv.Itoa(n))
+}
+
+// BadgeIncrement get badge changed by n
+func BadgeIncrement(n int) IOSBadge {
+	if n < 0 {
+		return IOSBadge(strconv.Itoa(n))
+	}
+	return IOSBadge("+" + strconv.Itoa(n))
+}
+
+// parse parse the badge of number N or +N/-N, ok is false if it is not
+func (b IOSBadge) parse() (sign string, n int, ok bool) {
+	s := string(b)
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign, s = s[:1], s[1:]
+	}
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return "", 0, false
+	}
+	return sign, n, true
 }
 
+// MarshalJSON marshal json, a plain number is marshaled as json number
+// and the leading zeros are dropped, such as "05" to 5 and "+05" to "+5"
+func (b IOSBadge) MarshalJSON() (data []byte, err error) {
+	sign, n, ok := b.parse()
+	if !ok {
+		return json.Marshal(string(b))
+	}
+	if sign == "" {
+		return []byte(strconv.Itoa(n)), nil
+	}
+	return json.Marshal(sign + strconv.Itoa(n))
+}
+
+// UnmarshalJSON unmarshal json
+func (b *IOSBadge) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = IOSBadge(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*b = IOSBadge(n.String())
+	return nil
+}
+
+// InterruptionLevel define ios 15 notification interruption level
+type InterruptionLevel string
+
+// ios notification interruption levels
+const (
+	InterruptionPassive       InterruptionLevel = "passive"
+	InterruptionActive        InterruptionLevel = "active"
+	InterruptionTimeSensitive InterruptionLevel = "time-sensitive"
+	InterruptionCritical      InterruptionLevel = "critical"
+)
+
 // NotificationIOS define ios notification
 type NotificationIOS struct {
-	Alert            interface{}            `json:"alert"`
-	Sound            string                 `json:"sound,omitempty"`
-	Badge            int                    `json:"badge,int,omitempty"`
-	ContentAvailable bool                   `json:"content-available,omitempty"`
-	MutableContent   bool                   `json:"mutable-content,omitempty"`
-	Category         string                 `json:"category,omitempty"`
-	Extras           map[string]interface{} `json:"extras,omitempty"`
+	Alert             interface{}            `json:"alert"`           // string 或 *IOSAlert
+	Sound             interface{}            `json:"sound,omitempty"` // string 或 *IOSSound
+	Badge             IOSBadge               `json:"badge,omitempty"`
+	ContentAvailable  bool                   `json:"content-available,omitempty"`
+	MutableContent    bool                   `json:"mutable-content,omitempty"`
+	Category          string                 `json:"category,omitempty"`
+	Extras            map[string]interface{} `json:"extras,omitempty"`
+	ThreadID          string                 `json:"thread-id,omitempty"`
+	InterruptionLevel InterruptionLevel      `json:"interruption-level,omitempty"`
+	RelevanceScore    float64                `json:"relevance-score,omitempty"` // 0.0~1.0
+	TargetContentID   string                 `json:"target-content-id,omitempty"`
+}
+
+// NotificationHMOS define harmonyos next notification
+type NotificationHMOS struct {
+	Alert       string                 `json:"alert"`
+	Title       string                 `json:"title,omitempty"`
+	Category    string                 `json:"category"` // 通知消息类别，必填，如 IM、ACCOUNT、MARKETING
+	Intent      map[string]interface{} `json:"intent,omitempty"`
+	LargeIcon   string                 `json:"large_icon,omitempty"`
+	BadgeAddNum int                    `json:"badge_add_num,omitempty"`
+	BadgeSetNum int                    `json:"badge_set_num,omitempty"`
+	Style       int                    `json:"style,omitempty"` // 0 默认样式，3 多行文本样式
+	Inbox       []string               `json:"inbox,omitempty"`
+	TestMessage bool                   `json:"test_message,omitempty"` // 测试消息，不受频控限制
+	ReceiptID   string                 `json:"receipt_id,omitempty"`   // 华为回执 id
+	Extras      map[string]interface{} `json:"extras,omitempty"`
+}
+
+// NotificationQuickApp define quickapp notification
+type NotificationQuickApp struct {
+	Alert  string                 `json:"alert"`
+	Title  string                 `json:"title"`
+	Page   string                 `json:"page"` // 快应用跳转页面路径
+	Extras map[string]interface{} `json:"extras,omitempty"`
 }
 
 // NotificationWinPhone define winphone notification
+//
+// Deprecated: winphone push is no longer supported by JPush
 type NotificationWinPhone struct {
 	Alert    string                 `json:"alert"`
 	Title    string                 `json:"title,omitempty"`
@@ -127,6 +317,27 @@ type PushMessage struct {
 	Extras      map[string]interface{} `json:"extras,omitempty"`
 }
 
+// InAppMessage define in-app message, show the notification in app when
+// the app is in foreground
+type InAppMessage struct {
+	InAppMessage bool `json:"inapp_message"`
+}
+
+// Notification3rd define custom message delivered as vendor notification
+// when the app is offline
+type Notification3rd struct {
+	Title       string                 `json:"title,omitempty"`
+	Content     string                 `json:"content"`
+	ChannelID   string                 `json:"channel_id,omitempty"`
+	URIActivity string                 `json:"uri_activity,omitempty"`
+	URIAction   string                 `json:"uri_action,omitempty"`
+	BadgeAddNum int                    `json:"badge_add_num,omitempty"`
+	BadgeSetNum int                    `json:"badge_set_num,omitempty"`
+	BadgeClass  string                 `json:"badge_class,omitempty"`
+	Sound       string                 `json:"sound,omitempty"`
+	Extras      map[string]interface{} `json:"extras,omitempty"`
+}
+
 // SmsMessage define sms message
 type SmsMessage struct {
 	DelayTime int                    `json:"delay_time,int"`
@@ -142,17 +353,24 @@ type PushOptions struct {
 	ApnsProduction  bool   `json:"apns_production"`
 	ApnsCollapseID  string `json:"apns_collapse_id,omitempty"`
 	BigPushDuration int    `json:"big_push_duration,int,omitempty"`
+	Classification  int    `json:"classification,omitempty"` // 0 运营消息，1 系统消息，用于 hmos 和厂商通道分类
+
+	ThirdPartyChannel *ThirdPartyChannel `json:"third_party_channel,omitempty"`
 }
 
 // PushRequest define push request body
 type PushRequest struct {
-	Cid          string            `json:"cid,omitempty"`
-	Platform     *Platform         `json:"platform"`
-	Audience     *PushAudience     `json:"audience"`
-	Notification *PushNotification `json:"notification,omitempty"`
-	Message      *PushMessage      `json:"message,omitempty"`
-	SmsMessage   *SmsMessage       `json:"sms_message,omitempty"`
-	Options      *PushOptions      `json:"options,omitempty"`
+	Cid             string            `json:"cid,omitempty"`
+	Platform        *Platform         `json:"platform"`
+	Audience        *PushAudience     `json:"audience"`
+	Notification    *PushNotification `json:"notification,omitempty"`
+	Notification3rd *Notification3rd  `json:"notification_3rd,omitempty"` // 需与 Message 一起使用
+	Message         *PushMessage      `json:"message,omitempty"`
+	InAppMessage    *InAppMessage     `json:"inapp_message,omitempty"` // 需与 Notification 一起使用
+	SmsMessage      *SmsMessage       `json:"sms_message,omitempty"`
+	LiveActivity    *LiveActivity     `json:"live_activity,omitempty"`
+	Options         *PushOptions      `json:"options,omitempty"`
+	Callback        *Callback         `json:"callback,omitempty"`
 }
 
 // PushResponse define push repsone
@@ -169,12 +387,18 @@ type PushCIDResponse struct {
 // Push push notification or message to devices
 // POST /v3/push
 func (j *JPush) Push(req *PushRequest) (*PushResponse, error) {
+	return j.PushContext(context.Background(), req)
+}
+
+// PushContext like Push, with ctx for cancellation and deadline
+func (j *JPush) PushContext(ctx context.Context, req *PushRequest) (*PushResponse, error) {
+	ctx = retryIfCid(ctx, req.Cid)
 	url := j.GetURL("push") + "push"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -189,12 +413,17 @@ func (j *JPush) Push(req *PushRequest) (*PushResponse, error) {
 // PushGetCid get push by cid
 // GET /v3/push/cid[?count=n[&type=xx]]
 func (j *JPush) PushGetCid(count int, cidtype string) (*PushCIDResponse, error) {
+	return j.PushGetCidContext(context.Background(), count, cidtype)
+}
+
+// PushGetCidContext like PushGetCid, with ctx for cancellation and deadline
+func (j *JPush) PushGetCidContext(ctx context.Context, count int, cidtype string) (*PushCIDResponse, error) {
 	url := j.GetURL("push") + "push/cid"
 	params := make(map[string]string)
 	params["count"] = strconv.Itoa(count)
 	params["type"] = cidtype
 
-	resp, err := j.request("GET", url, nil, params)
+	resp, err := j.request(ctx, "GET", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -209,12 +438,18 @@ func (j *JPush) PushGetCid(count int, cidtype string) (*PushCIDResponse, error)
 // GroupPush group push
 // POST /v3/grouppush
 func (j *GroupPush) GroupPush(req *PushRequest) (*PushResponse, error) {
+	return j.GroupPushContext(context.Background(), req)
+}
+
+// GroupPushContext like GroupPush, with ctx for cancellation and deadline
+func (j *GroupPush) GroupPushContext(ctx context.Context, req *PushRequest) (*PushResponse, error) {
+	ctx = retryIfCid(ctx, req.Cid)
 	url := j.GetURL("push") + "grouppush"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -229,12 +464,17 @@ func (j *GroupPush) GroupPush(req *PushRequest) (*PushResponse, error) {
 // PushValidate push validate, not real push
 // POST /v3/push/validate
 func (j *JPush) PushValidate(req *PushRequest) (*PushResponse, error) {
+	return j.PushValidateContext(context.Background(), req)
+}
+
+// PushValidateContext like PushValidate, with ctx for cancellation and deadline
+func (j *JPush) PushValidateContext(ctx context.Context, req *PushRequest) (*PushResponse, error) {
 	url := j.GetURL("push") + "push/validate"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -245,3 +485,96 @@ func (j *JPush) PushValidate(req *PushRequest) (*PushResponse, error) {
 	}
 	return ret, nil
 }
+
+// BatchPushItem define one push of batch single push, target is the
+// registration id or alias
+type BatchPushItem struct {
+	Platform     *Platform         `json:"platform"`
+	Target       string            `json:"target"`
+	Notification *PushNotification `json:"notification,omitempty"`
+	Message      *PushMessage      `json:"message,omitempty"`
+	SmsMessage   *SmsMessage       `json:"sms_message,omitempty"`
+	Options      *PushOptions      `json:"options,omitempty"`
+	Callback     *Callback         `json:"callback,omitempty"`
+}
+
+// BatchPushRequest define batch single push request body, key of PushList is cid
+type BatchPushRequest struct {
+	PushList map[string]*BatchPushItem `json:"pushlist"`
+}
+
+// BatchPushResult define result of one push in batch, keyed by cid
+type BatchPushResult struct {
+	MsgID string        `json:"msg_id,omitempty"`
+	Error *ErrorMessage `json:"error,omitempty"`
+}
+
+// BatchPushByRegID push different payloads to registration ids
+// POST /v3/push/batch/regid/single
+func (j *JPush) BatchPushByRegID(req *BatchPushRequest) (map[string]BatchPushResult, error) {
+	return j.BatchPushByRegIDContext(context.Background(), req)
+}
+
+// BatchPushByRegIDContext like BatchPushByRegID, with ctx for cancellation and deadline
+func (j *JPush) BatchPushByRegIDContext(ctx context.Context, req *BatchPushRequest) (map[string]BatchPushResult, error) {
+	return j.batchPush(ctx, j.GetURL("push")+"push/batch/regid/single", req)
+}
+
+// BatchPushByAlias push different payloads to aliases
+// POST /v3/push/batch/alias/single
+func (j *JPush) BatchPushByAlias(req *BatchPushRequest) (map[string]BatchPushResult, error) {
+	return j.BatchPushByAliasContext(context.Background(), req)
+}
+
+// BatchPushByAliasContext like BatchPushByAlias, with ctx for cancellation and deadline
+func (j *JPush) BatchPushByAliasContext(ctx context.Context, req *BatchPushRequest) (map[string]BatchPushResult, error) {
+	return j.batchPush(ctx, j.GetURL("push")+"push/batch/alias/single", req)
+}
+
+// batchPush send batch single push request to url
+func (j *JPush) batchPush(ctx context.Context, url string, req *BatchPushRequest) (map[string]BatchPushResult, error) {
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	// each push is keyed by cid, it is safe to retry
+	resp, err := j.request(RetryContext(ctx), "POST", url, bytes.NewReader(buf), nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(map[string]BatchPushResult)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return *ret, nil
+}
+
+// PushWithdraw withdraw the push by msg id, errors.Is the error with
+// ErrAlreadyDelivered or ErrWithdrawTooOld to check why withdraw failed
+// DELETE /v3/push/{msg_id}
+func (j *JPush) PushWithdraw(msgID string) (*DefaultResponse, error) {
+	return j.PushWithdrawContext(context.Background(), msgID)
+}
+
+// PushWithdrawContext like PushWithdraw, with ctx for cancellation and deadline
+func (j *JPush) PushWithdrawContext(ctx context.Context, msgID string) (*DefaultResponse, error) {
+	url := j.GetURL("push") + "push/" + msgID
+
+	resp, err := j.request(ctx, "DELETE", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(DefaultResponse)
+	err = json.Unmarshal(resp, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Withdraw withdraw this push by client
+func (r *PushResponse) Withdraw(client *JPush) error {
+	_, err := client.PushWithdraw(r.MsgID)
+	return err
+}
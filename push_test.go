@@ -0,0 +1,94 @@
+package jpush_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+func TestBatchPush(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	client := srv.JPushClient()
+	platform := &jpush.Platform{Platforms: []string{jpush.PlatformAndroid}}
+	req := &jpush.BatchPushRequest{PushList: map[string]*jpush.BatchPushItem{
+		"appkey-ok": {
+			Platform:     platform,
+			Target:       "target1",
+			Notification: &jpush.PushNotification{Alert: "hello"},
+		},
+		"appkey-no-target": {
+			Platform:     platform,
+			Notification: &jpush.PushNotification{Alert: "hello"},
+		},
+		"appkey-no-payload": {
+			Platform: platform,
+			Target:   "target2",
+		},
+	}}
+
+	for name, batch := range map[string]func(*jpush.BatchPushRequest) (map[string]jpush.BatchPushResult, error){
+		"regid": client.BatchPushByRegID,
+		"alias": client.BatchPushByAlias,
+	} {
+		srv.Reset()
+		ret, err := batch(req)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(ret) != 3 {
+			t.Errorf("%s: got %d results, want 3", name, len(ret))
+		}
+		push, ok := srv.LastPush()
+		if !ok || ret["appkey-ok"].Error != nil || ret["appkey-ok"].MsgID != push.MsgID {
+			t.Errorf("%s: got result %+v, want msg_id of the push %+v", name, ret["appkey-ok"], push)
+		}
+		aud := push.Request.Audience.Aud
+		if (name == "regid" && (len(aud.RegistrationID) != 1 || aud.RegistrationID[0] != "target1")) ||
+			(name == "alias" && (len(aud.Alias) != 1 || aud.Alias[0] != "target1")) {
+			t.Errorf("%s: got audience %+v, want target1", name, aud)
+		}
+		for _, cid := range []string{"appkey-no-target", "appkey-no-payload"} {
+			if r := ret[cid]; r.MsgID != "" || r.Error == nil || r.Error.Code != jpush.CodeMissingParam {
+				t.Errorf("%s: got result %+v of %s, want missing param error", name, r, cid)
+			}
+		}
+		if n := len(srv.Pushes()); n != 1 {
+			t.Errorf("%s: server got %d pushes, want 1", name, n)
+		}
+	}
+}
+
+func TestPushWithdraw(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	client := srv.JPushClient()
+	req := newPush(t)
+
+	ret, err := client.Push(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ret.Withdraw(client); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.Withdrawn(ret.MsgID) {
+		t.Errorf("push %s not withdrawn", ret.MsgID)
+	}
+
+	ret, err = client.Push(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetDelivered(ret.MsgID)
+	if err := ret.Withdraw(client); !errors.Is(err, jpush.ErrAlreadyDelivered) {
+		t.Errorf("got %v, want ErrAlreadyDelivered", err)
+	}
+
+	srv.InjectError(jpushtest.EndpointWithdraw, jpushtest.Fault{Status: 400, Code: jpush.CodeWithdrawTooOld, Message: "too old", Times: 1})
+	if _, err := client.PushWithdraw(ret.MsgID); !errors.Is(err, jpush.ErrWithdrawTooOld) {
+		t.Errorf("got %v, want ErrWithdrawTooOld", err)
+	}
+}
@@ -0,0 +1,141 @@
+package jpush
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrThrottled the request is rejected by the client side throttle
+// because the rate limit quota is exhausted
+var ErrThrottled = errors.New("jpush: rate limit quota exhausted, request throttled")
+
+// ThrottleMode define client side throttle mode
+type ThrottleMode int
+
+// client side throttle mode
+const (
+	ThrottleNone     ThrottleMode = iota // 不限制，由服务端返回频率超限错误
+	ThrottleBlock                        // 配额用尽时阻塞等待时间窗口重置
+	ThrottleFailFast                     // 配额用尽时直接返回 ErrThrottled
+)
+
+// RateLimit rate limit state snapshot from the api response headers
+type RateLimit struct {
+	Quota     int       // 当前 AppKey 一个时间窗口内可调用次数
+	Remaining int       // 当前时间窗口剩余的可用次数
+	Reset     int       // 距离时间窗口重置剩余的秒数
+	UpdatedAt time.Time // 获取以上数据的时间
+}
+
+// ResetAt get the time when the rate limit window resets
+func (r RateLimit) ResetAt() time.Time {
+	return r.UpdatedAt.Add(time.Duration(r.Reset) * time.Second)
+}
+
+// rateLimiter concurrency safe rate limit state
+type rateLimiter struct {
+	mu    sync.Mutex
+	state RateLimit
+	mode  ThrottleMode
+}
+
+// snapshot get the current rate limit state
+func (l *rateLimiter) snapshot() RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// parseRateLimit parse rate limit state from response header
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	var limit RateLimit
+	quota, errQuota := strconv.Atoi(header.Get("X-Rate-Limit-Quota"))
+	remaining, errRemaining := strconv.Atoi(header.Get("X-Rate-Limit-Remaining"))
+	reset, errReset := strconv.Atoi(header.Get("X-Rate-Limit-Reset"))
+	if errQuota != nil || errRemaining != nil || errReset != nil {
+		return limit, false
+	}
+	limit.Quota = quota
+	limit.Remaining = remaining
+	limit.Reset = reset
+	limit.UpdatedAt = time.Now()
+	return limit, true
+}
+
+// updateRateLimit update the rate limit state from response header
+func (j *JPush) updateRateLimit(header http.Header) {
+	limit, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+	j.limiter.mu.Lock()
+	defer j.limiter.mu.Unlock()
+	j.limiter.state = limit
+	// kept for the callers of the deprecated fields, the lock does not
+	// protect their readers
+	j.Quota, j.Remaining, j.Reset = limit.Quota, limit.Remaining, limit.Reset
+}
+
+// acquire take one call from the quota of current window before a request,
+// block or fail according to the throttle mode when the quota is exhausted
+func (l *rateLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.mode == ThrottleNone || l.state.UpdatedAt.IsZero() {
+			l.mu.Unlock()
+			return nil
+		}
+		resetAt := l.state.ResetAt()
+		if !time.Now().Before(resetAt) {
+			// window reset, the server will report the new state
+			l.mu.Unlock()
+			return nil
+		}
+		if l.state.Remaining > 0 {
+			l.state.Remaining--
+			l.mu.Unlock()
+			return nil
+		}
+		mode := l.mode
+		l.mu.Unlock()
+
+		if mode == ThrottleFailFast {
+			return ErrThrottled
+		}
+		timer := time.NewTimer(time.Until(resetAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// RateLimit get the rate limit state of the last response
+func (j *JPush) RateLimit() RateLimit {
+	return j.limiter.snapshot()
+}
+
+// SetThrottle set client side throttle mode
+func (j *JPush) SetThrottle(mode ThrottleMode) {
+	j.limiter.mu.Lock()
+	j.limiter.mode = mode
+	j.limiter.mu.Unlock()
+}
+
+// waitQuota wait for the rate limit window reset when the quota is exhausted
+func (j *JPush) waitQuota(ctx context.Context) error {
+	limit := j.RateLimit()
+	if limit.UpdatedAt.IsZero() || limit.Remaining > 0 {
+		return nil
+	}
+	if !sleep(ctx, time.Until(limit.ResetAt())) {
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package jpush_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+// concurrentPush push req n times concurrently, return the errors
+func concurrentPush(ctx context.Context, client *jpush.JPush, req *jpush.PushRequest, n int) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.PushContext(ctx, req)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func newPush(t *testing.T) *jpush.PushRequest {
+	req, err := jpush.NewPush().Android().ToAll().Alert("hello").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestThrottleFailFast(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetRateLimit(100, 6, time.Minute)
+	client := srv.JPushClient(jpush.WithThrottle(jpush.ThrottleFailFast))
+	req := newPush(t)
+
+	if _, err := client.Push(req); err != nil {
+		t.Fatal(err)
+	}
+	succeeded, throttled := 0, 0
+	for _, err := range concurrentPush(context.Background(), client, req, 20) {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, jpush.ErrThrottled):
+			throttled++
+		case !jpush.IsRateLimited(err):
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	if succeeded != 5 {
+		t.Errorf("got %d succeeded, want the remaining 5", succeeded)
+	}
+	if throttled == 0 {
+		t.Error("no request throttled on client side")
+	}
+	if n := len(srv.Pushes()); n != 6 {
+		t.Errorf("server got %d pushes, want 6", n)
+	}
+}
+
+func TestThrottleBlock(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetRateLimit(100, 3, time.Second)
+	client := srv.JPushClient(jpush.WithThrottle(jpush.ThrottleBlock))
+	req := newPush(t)
+
+	if _, err := client.Push(req); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for _, err := range concurrentPush(context.Background(), client, req, 5) {
+		if err != nil {
+			t.Errorf("push: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("pushes over the quota returned after %v, want blocked until the window reset", elapsed)
+	}
+	if n := len(srv.Pushes()); n != 6 {
+		t.Errorf("server got %d pushes, want 6", n)
+	}
+}
+
+func TestThrottleBlockCanceled(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetRateLimit(100, 1, time.Minute)
+	client := srv.JPushClient(jpush.WithThrottle(jpush.ThrottleBlock))
+	req := newPush(t)
+
+	if _, err := client.Push(req); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for _, err := range concurrentPush(ctx, client, req, 3) {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want deadline exceeded", err)
+		}
+	}
+}
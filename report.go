@@ -2,19 +2,123 @@ package jpush
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// ReportTime report time
+// MaxReportMsgIDs max msg ids of each report call
+const MaxReportMsgIDs = 100
+
+// reportConcurrency max concurrent calls of a split report
+const reportConcurrency = 4
+
+// Beijing time zone of the report times
+var Beijing = time.FixedZone("CST", 8*3600)
+
+// TimeUnit time unit of users report
+type TimeUnit string
+
+// users report time units
+const (
+	TimeUnitHour  TimeUnit = "HOUR"  // 每次最多 24 小时
+	TimeUnitDay   TimeUnit = "DAY"   // 每次最多 60 天
+	TimeUnitMonth TimeUnit = "MONTH" // 每次最多 2 个月
+)
+
+// layout get the time layout of unit
+func (u TimeUnit) layout() string {
+	switch u {
+	case TimeUnitHour:
+		return "2006-01-02 15"
+	case TimeUnitMonth:
+		return "2006-01"
+	}
+	return "2006-01-02"
+}
+
+// maxDuration get the max duration of one users report call
+func (u TimeUnit) maxDuration() int {
+	switch u {
+	case TimeUnitHour:
+		return 24
+	case TimeUnitDay:
+		return 60
+	case TimeUnitMonth:
+		return 2
+	}
+	return 0
+}
+
+// truncate get the start of unit containing t in Beijing time
+func (u TimeUnit) truncate(t time.Time) time.Time {
+	t = t.In(Beijing)
+	switch u {
+	case TimeUnitHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, Beijing)
+	case TimeUnitMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, Beijing)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Beijing)
+}
+
+// add get t added n units
+func (u TimeUnit) add(t time.Time, n int) time.Time {
+	switch u {
+	case TimeUnitHour:
+		return t.Add(time.Duration(n) * time.Hour)
+	case TimeUnitMonth:
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// sameCall check if the units starting at a and b can be in one users
+// report call, the hour stats of one call are of one day in Beijing time
+func (u TimeUnit) sameCall(a, b time.Time) bool {
+	if u != TimeUnitHour {
+		return true
+	}
+	a, b = a.In(Beijing), b.In(Beijing)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// MsgID msg id of report responses, decoded losslessly from json number
+// or string, a msg id has more digits than float64 can keep
+type MsgID string
+
+// UnmarshalJSON unmarshal json number or string
+func (m *MsgID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = MsgID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*m = MsgID(n.String())
+	return nil
+}
+
+// ReportTime report time in Beijing time
 type ReportTime time.Time
 
-// UnmarshalJSON unmarshal json
+// UnmarshalJSON unmarshal json of day, hour or month layout
 func (r *ReportTime) UnmarshalJSON(data []byte) error {
-	t, err := time.Parse("2006-01-02", string(data))
+	var t time.Time
+	var err error
+	for _, layout := range []string{`"2006-01-02"`, `"2006-01-02 15"`, `"2006-01"`} {
+		t, err = time.ParseInLocation(layout, string(data), Beijing)
+		if err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -22,9 +126,10 @@ func (r *ReportTime) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// MarshalJSON marshal json
+// MarshalJSON marshal json of day layout, ReportUsersResponse marshal the
+// times with the layout of its time unit
 func (r *ReportTime) MarshalJSON() (data []byte, err error) {
-	t := time.Time(*r).Format("2006-01-02")
+	t := time.Time(*r).In(Beijing).Format(`"2006-01-02"`)
 	return []byte(t), nil
 }
 
@@ -37,12 +142,12 @@ type ReportStatusRequest struct {
 
 // ReportReceivedResponse define report received
 type ReportReceivedResponse struct {
-	MsgID           float64 `json:"msg_id"`
-	AndroidReceived int     `json:"android_received"`
-	IOSApnsSent     int     `json:"ios_apns_sent"`
-	IOSApnsReceived int     `json:"ios_apns_received"`
-	IOSMsgReceived  int     `json:"ios_msg_received"`
-	WpMpnsSent      int     `json:"wp_mpns_sent"`
+	MsgID           MsgID `json:"msg_id"`
+	AndroidReceived int   `json:"android_received"`
+	IOSApnsSent     int   `json:"ios_apns_sent"`
+	IOSApnsReceived int   `json:"ios_apns_received"`
+	IOSMsgReceived  int   `json:"ios_msg_received"`
+	WpMpnsSent      int   `json:"wp_mpns_sent"`
 }
 
 // MessageStatus message status
@@ -78,7 +183,7 @@ type ReportWpMessage struct {
 
 // ReportMessagesResponse define report messages
 type ReportMessagesResponse struct {
-	MsgID   string                `json:"msg_id,omitempty"`
+	MsgID   MsgID                 `json:"msg_id,omitempty"`
 	Android *ReportAndroidMessage `json:"android,omitempty"`
 	IOS     *ReportIOSMessage     `json:"ios,omitempty"`
 	Wp      *ReportWpMessage      `json:"winphone,omitempty"`
@@ -107,40 +212,164 @@ type ReportUser struct {
 
 // ReportUsersResponse define report user response
 type ReportUsersResponse struct {
-	TimeUnit string       `json:"time_unit"`
+	TimeUnit TimeUnit     `json:"time_unit"`
 	Start    *ReportTime  `json:"start"`
 	Duration int          `json:"duration"`
 	Items    []ReportUser `json:"items"`
 }
 
-// ReportReceived report received
+// reportUserJSON users report item with time formatted of time unit
+type reportUserJSON struct {
+	Time    *string            `json:"time"`
+	Android *ReportUserAndroid `json:"android,omitempty"`
+	IOS     *ReportUserIOS     `json:"ios,omitempty"`
+}
+
+// MarshalJSON marshal json, start and item times are formatted with the
+// layout of TimeUnit, as the server returns them
+func (r ReportUsersResponse) MarshalJSON() ([]byte, error) {
+	layout := r.TimeUnit.layout()
+	format := func(t *ReportTime) *string {
+		if t == nil {
+			return nil
+		}
+		s := time.Time(*t).In(Beijing).Format(layout)
+		return &s
+	}
+	var items []reportUserJSON
+	if r.Items != nil {
+		items = make([]reportUserJSON, len(r.Items))
+	}
+	for i, item := range r.Items {
+		items[i] = reportUserJSON{Time: format(item.Time), Android: item.Android, IOS: item.IOS}
+	}
+	return json.Marshal(struct {
+		TimeUnit TimeUnit         `json:"time_unit"`
+		Start    *string          `json:"start"`
+		Duration int              `json:"duration"`
+		Items    []reportUserJSON `json:"items"`
+	}{r.TimeUnit, format(r.Start), r.Duration, items})
+}
+
+// ReportReceivedDetailResponse define received detail of each channel
+type ReportReceivedDetailResponse struct {
+	MsgID                 MsgID `json:"msg_id"`
+	JGReceived            int   `json:"jg_received"`             // 极光通道送达
+	AndroidPnsSent        int   `json:"android_pns_sent"`        // Android 厂商通道成功推送
+	AndroidPnsReceived    int   `json:"android_pns_received"`    // Android 厂商通道送达
+	IOSApnsSent           int   `json:"ios_apns_sent"`           // APNs 成功推送
+	IOSApnsReceived       int   `json:"ios_apns_received"`       // APNs 送达
+	IOSMsgReceived        int   `json:"ios_msg_received"`        // iOS 自定义消息送达
+	QuickAppJPushReceived int   `json:"quickapp_jpush_received"` // 快应用极光通道送达
+	QuickAppPnsSent       int   `json:"quickapp_pns_sent"`       // 快应用厂商通道成功推送
+	HMOSHmpnsSent         int   `json:"hmos_hmpns_sent"`         // 鸿蒙通道成功推送
+	HMOSHmpnsReceived     int   `json:"hmos_hmpns_received"`     // 鸿蒙通道送达
+}
+
+// ReportChannelStat define stat of one channel
+type ReportChannelStat struct {
+	Target   int `json:"target"`
+	Sent     int `json:"sent"`
+	Received int `json:"received"`
+	Display  int `json:"display"`
+	Click    int `json:"click"`
+}
+
+// ReportAndroidDetail define android stat of jpush and vendor channels
+type ReportAndroidDetail struct {
+	JPush  *ReportChannelStat `json:"jg_android,omitempty"`
+	Xiaomi *ReportChannelStat `json:"xm_android,omitempty"`
+	Huawei *ReportChannelStat `json:"hw_android,omitempty"`
+	Honor  *ReportChannelStat `json:"honor_android,omitempty"`
+	Oppo   *ReportChannelStat `json:"oppo_android,omitempty"`
+	Vivo   *ReportChannelStat `json:"vivo_android,omitempty"`
+	Meizu  *ReportChannelStat `json:"mz_android,omitempty"`
+	Fcm    *ReportChannelStat `json:"fcm_android,omitempty"`
+}
+
+// ReportIOSDetail define ios stat of apns and jpush channels
+type ReportIOSDetail struct {
+	Apns  *ReportChannelStat `json:"apns_ios,omitempty"`
+	JPush *ReportChannelStat `json:"jg_ios,omitempty"`
+}
+
+// ReportQuickAppDetail define quickapp stat of jpush and vendor channels
+type ReportQuickAppDetail struct {
+	JPush  *ReportChannelStat `json:"jg_quickapp,omitempty"`
+	Vendor *ReportChannelStat `json:"pns_quickapp,omitempty"`
+}
+
+// ReportHMOSDetail define harmonyos next stat
+type ReportHMOSDetail struct {
+	Hmpns *ReportChannelStat `json:"hmpns_hmos,omitempty"`
+}
+
+// ReportDetailStat define total and per platform stat of one message type
+type ReportDetailStat struct {
+	ReportChannelStat
+	Android  *ReportAndroidDetail  `json:"sub_android,omitempty"`
+	IOS      *ReportIOSDetail      `json:"sub_ios,omitempty"`
+	QuickApp *ReportQuickAppDetail `json:"sub_quickapp,omitempty"`
+	HMOS     *ReportHMOSDetail     `json:"sub_hmos,omitempty"`
+}
+
+// ReportMessagesDetails define stat of each message type
+type ReportMessagesDetails struct {
+	Notification *ReportDetailStat `json:"notification,omitempty"`
+	Message      *ReportDetailStat `json:"message,omitempty"`
+	InApp        *ReportDetailStat `json:"inapp,omitempty"`
+}
+
+// ReportMessagesDetailResponse define messages detail
+type ReportMessagesDetailResponse struct {
+	MsgID   MsgID                  `json:"msg_id"`
+	Details *ReportMessagesDetails `json:"details"`
+}
+
+// GroupReportMessagesDetailResponse define group messages detail
+type GroupReportMessagesDetailResponse struct {
+	GroupMsgID MsgID                  `json:"group_msgid"`
+	Details    *ReportMessagesDetails `json:"details"`
+}
+
+// ReportReceived report received, msgIds more than MaxReportMsgIDs are
+// split into concurrent calls and the results are merged in order
 // GET /v3/received
 func (j *JPush) ReportReceived(msgIds []string) ([]ReportReceivedResponse, error) {
-	url := j.GetURL("report") + "received"
-	params := make(map[string]string)
-	params["msg_ids"] = strings.Join(msgIds, ",")
+	return j.ReportReceivedContext(context.Background(), msgIds)
+}
 
-	resp, err := j.request("GET", url, nil, params)
+// ReportReceivedContext like ReportReceived, with ctx for cancellation and deadline
+func (j *JPush) ReportReceivedContext(ctx context.Context, msgIds []string) ([]ReportReceivedResponse, error) {
+	chunks := splitIDs(msgIds, MaxReportMsgIDs)
+	rets := make([][]ReportReceivedResponse, len(chunks))
+	err := runChunks(ctx, len(chunks), func(ctx context.Context, i int) error {
+		return j.report(ctx, "received", "msg_ids", chunks[i], &rets[i])
+	})
 	if err != nil {
 		return nil, err
 	}
-	ret := new([]ReportReceivedResponse)
-	err = json.Unmarshal(resp, ret)
-	if err != nil {
-		return nil, err
+	ret := make([]ReportReceivedResponse, 0, len(msgIds))
+	for _, r := range rets {
+		ret = append(ret, r...)
 	}
-	return *ret, nil
+	return ret, nil
 }
 
 // ReportStatus report push status
 // POST /v3/status/message
 func (j *JPush) ReportStatus(req *ReportStatusRequest) (map[string]MessageStatus, error) {
+	return j.ReportStatusContext(context.Background(), req)
+}
+
+// ReportStatusContext like ReportStatus, with ctx for cancellation and deadline
+func (j *JPush) ReportStatusContext(ctx context.Context, req *ReportStatusRequest) (map[string]MessageStatus, error) {
 	url := j.GetURL("report") + "status/message"
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -152,18 +381,51 @@ func (j *JPush) ReportStatus(req *ReportStatusRequest) (map[string]MessageStatus
 	return *ret, nil
 }
 
-// ReportMessages message stat
+// ReportMessages message stat of each msg id, msgIds more than
+// MaxReportMsgIDs are split into concurrent calls and the results are
+// merged in order
 // GET /v3/messages
-func (j *JPush) ReportMessages(msgIds []string) (*ReportMessagesResponse, error) {
-	url := j.GetURL("report") + "messages"
-	params := make(map[string]string)
-	params["msg_ids"] = strings.Join(msgIds, ",")
+func (j *JPush) ReportMessages(msgIds []string) ([]ReportMessagesResponse, error) {
+	return j.ReportMessagesContext(context.Background(), msgIds)
+}
+
+// ReportMessagesContext like ReportMessages, with ctx for cancellation and deadline
+func (j *JPush) ReportMessagesContext(ctx context.Context, msgIds []string) ([]ReportMessagesResponse, error) {
+	chunks := splitIDs(msgIds, MaxReportMsgIDs)
+	rets := make([][]ReportMessagesResponse, len(chunks))
+	err := runChunks(ctx, len(chunks), func(ctx context.Context, i int) error {
+		return j.report(ctx, "messages", "msg_ids", chunks[i], &rets[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]ReportMessagesResponse, 0, len(msgIds))
+	for _, r := range rets {
+		ret = append(ret, r...)
+	}
+	return ret, nil
+}
+
+// ReportUsers user stat, the hour stats are only of the day of start in
+// Beijing time, use ReportUsersRange for hours across days
+// GET /v3/users
+func (j *JPush) ReportUsers(timeUnit TimeUnit, start time.Time, duration int) (*ReportUsersResponse, error) {
+	return j.ReportUsersContext(context.Background(), timeUnit, start, duration)
+}
+
+// ReportUsersContext like ReportUsers, with ctx for cancellation and deadline
+func (j *JPush) ReportUsersContext(ctx context.Context, timeUnit TimeUnit, start time.Time, duration int) (*ReportUsersResponse, error) {
+	url := j.GetURL("report") + "users"
+	params, err := reportUsersParams(timeUnit, start, duration)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := j.request("GET", url, nil, params)
+	resp, err := j.request(ctx, "GET", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
-	ret := new(ReportMessagesResponse)
+	ret := new(ReportUsersResponse)
 	err = json.Unmarshal(resp, ret)
 	if err != nil {
 		return nil, err
@@ -171,25 +433,157 @@ func (j *JPush) ReportMessages(msgIds []string) (*ReportMessagesResponse, error)
 	return ret, nil
 }
 
-// ReportUsers user stat
-// GET /v3/users
-func (j *JPush) ReportUsers(timeUnit string, start time.Time, duration int) (*ReportUsersResponse, error) {
-	url := j.GetURL("report") + "users"
-	params := make(map[string]string)
-	params["time_unit"] = timeUnit
-	params["start"] = ""
-	if timeUnit == "HOUR" {
-		params["start"] = start.Format("2006-01-02 15")
-	} else if timeUnit == "DAY" {
-		params["start"] = start.Format("2006-01-02")
-	} else if timeUnit == "MONTH" {
-		params["start"] = start.Format("2006-01")
-	} else {
+// reportUsersParams get the query params of users report, start is
+// formatted in Beijing time
+func reportUsersParams(timeUnit TimeUnit, start time.Time, duration int) (map[string]string, error) {
+	if timeUnit.maxDuration() == 0 {
 		return nil, errors.New("Bad Request: wrong time unit")
 	}
+	params := make(map[string]string)
+	params["time_unit"] = string(timeUnit)
+	params["start"] = start.In(Beijing).Format(timeUnit.layout())
 	params["duration"] = strconv.Itoa(duration)
+	return params, nil
+}
+
+// ReportUsersRange user stat of each unit from the unit containing from to
+// the unit containing to, the range is split into calls within the duration
+// limit of unit and the Beijing day of hours, the items are merged in time order
+func (j *JPush) ReportUsersRange(timeUnit TimeUnit, from, to time.Time) (*ReportUsersResponse, error) {
+	return j.ReportUsersRangeContext(context.Background(), timeUnit, from, to)
+}
+
+// ReportUsersRangeContext like ReportUsersRange, with ctx for cancellation and deadline
+func (j *JPush) ReportUsersRangeContext(ctx context.Context, timeUnit TimeUnit, from, to time.Time) (*ReportUsersResponse, error) {
+	limit := timeUnit.maxDuration()
+	if limit == 0 {
+		return nil, errors.New("Bad Request: wrong time unit")
+	}
+	start, end := timeUnit.truncate(from), timeUnit.truncate(to)
+	if end.Before(start) {
+		return nil, errors.New("Bad Request: to is before from")
+	}
+	type window struct {
+		start    time.Time
+		duration int
+	}
+	var windows []window
+	total := 0
+	for t := start; !t.After(end); {
+		n := 1
+		for n < limit && !timeUnit.add(t, n).After(end) && timeUnit.sameCall(t, timeUnit.add(t, n)) {
+			n++
+		}
+		windows = append(windows, window{t, n})
+		total += n
+		t = timeUnit.add(t, n)
+	}
+
+	rets := make([]*ReportUsersResponse, len(windows))
+	err := runChunks(ctx, len(windows), func(ctx context.Context, i int) error {
+		ret, err := j.ReportUsersContext(ctx, timeUnit, windows[i].start, windows[i].duration)
+		rets[i] = ret
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	first := ReportTime(start)
+	ret := &ReportUsersResponse{
+		TimeUnit: timeUnit,
+		Start:    &first,
+		Duration: total,
+	}
+	for _, r := range rets {
+		ret.Items = append(ret.Items, r.Items...)
+	}
+	return ret, nil
+}
+
+// ReportReceivedDetail received stat of each channel
+// GET /v3/received/detail
+func (j *JPush) ReportReceivedDetail(msgIds []string) ([]ReportReceivedDetailResponse, error) {
+	return j.ReportReceivedDetailContext(context.Background(), msgIds)
+}
+
+// ReportReceivedDetailContext like ReportReceivedDetail, with ctx for cancellation and deadline
+func (j *JPush) ReportReceivedDetailContext(ctx context.Context, msgIds []string) ([]ReportReceivedDetailResponse, error) {
+	chunks := splitIDs(msgIds, MaxReportMsgIDs)
+	rets := make([][]ReportReceivedDetailResponse, len(chunks))
+	err := runChunks(ctx, len(chunks), func(ctx context.Context, i int) error {
+		return j.report(ctx, "received/detail", "msg_ids", chunks[i], &rets[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]ReportReceivedDetailResponse, 0, len(msgIds))
+	for _, r := range rets {
+		ret = append(ret, r...)
+	}
+	return ret, nil
+}
+
+// ReportMessagesDetail message stat of each platform and channel
+// GET /v3/messages/detail
+func (j *JPush) ReportMessagesDetail(msgIds []string) ([]ReportMessagesDetailResponse, error) {
+	return j.ReportMessagesDetailContext(context.Background(), msgIds)
+}
+
+// ReportMessagesDetailContext like ReportMessagesDetail, with ctx for cancellation and deadline
+func (j *JPush) ReportMessagesDetailContext(ctx context.Context, msgIds []string) ([]ReportMessagesDetailResponse, error) {
+	chunks := splitIDs(msgIds, MaxReportMsgIDs)
+	rets := make([][]ReportMessagesDetailResponse, len(chunks))
+	err := runChunks(ctx, len(chunks), func(ctx context.Context, i int) error {
+		return j.report(ctx, "messages/detail", "msg_ids", chunks[i], &rets[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]ReportMessagesDetailResponse, 0, len(msgIds))
+	for _, r := range rets {
+		ret = append(ret, r...)
+	}
+	return ret, nil
+}
+
+// GroupReportMessagesDetail group message stat of each platform and channel
+// GET /v3/group/messages/detail
+func (j *GroupPush) GroupReportMessagesDetail(groupMsgIds []string) ([]GroupReportMessagesDetailResponse, error) {
+	return j.GroupReportMessagesDetailContext(context.Background(), groupMsgIds)
+}
 
-	resp, err := j.request("GET", url, nil, params)
+// GroupReportMessagesDetailContext like GroupReportMessagesDetail, with ctx for cancellation and deadline
+func (j *GroupPush) GroupReportMessagesDetailContext(ctx context.Context, groupMsgIds []string) ([]GroupReportMessagesDetailResponse, error) {
+	chunks := splitIDs(groupMsgIds, MaxReportMsgIDs)
+	rets := make([][]GroupReportMessagesDetailResponse, len(chunks))
+	err := runChunks(ctx, len(chunks), func(ctx context.Context, i int) error {
+		return j.report(ctx, "group/messages/detail", "group_msgids", chunks[i], &rets[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]GroupReportMessagesDetailResponse, 0, len(groupMsgIds))
+	for _, r := range rets {
+		ret = append(ret, r...)
+	}
+	return ret, nil
+}
+
+// GroupReportUsers group user stat
+// GET /v3/group/users
+func (j *GroupPush) GroupReportUsers(timeUnit TimeUnit, start time.Time, duration int) (*ReportUsersResponse, error) {
+	return j.GroupReportUsersContext(context.Background(), timeUnit, start, duration)
+}
+
+// GroupReportUsersContext like GroupReportUsers, with ctx for cancellation and deadline
+func (j *GroupPush) GroupReportUsersContext(ctx context.Context, timeUnit TimeUnit, start time.Time, duration int) (*ReportUsersResponse, error) {
+	url := j.GetURL("report") + "group/users"
+	params, err := reportUsersParams(timeUnit, start, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := j.request(ctx, "GET", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -200,3 +594,74 @@ func (j *JPush) ReportUsers(timeUnit string, start time.Time, duration int) (*Re
 	}
 	return ret, nil
 }
+
+// report get the report of ids and unmarshal into v
+func (j *JPush) report(ctx context.Context, path, name string, ids []string, v interface{}) error {
+	url := j.GetURL("report") + path
+	params := make(map[string]string)
+	params[name] = strings.Join(ids, ",")
+
+	resp, err := j.request(ctx, "GET", url, nil, params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resp, v)
+}
+
+// splitIDs split ids into chunks of size, empty ids get one empty chunk
+// so the server reports the missing ids
+func splitIDs(ids []string, size int) [][]string {
+	if len(ids) == 0 {
+		return [][]string{ids}
+	}
+	var chunks [][]string
+	for i := 0; i < len(ids); i += size {
+		end := i + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[i:end])
+	}
+	return chunks
+}
+
+// runChunks call fn of n chunks concurrently, return the first error and
+// cancel the other calls
+func runChunks(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	if n == 1 {
+		return fn(ctx, 0)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, reportConcurrency)
+	started := 0
+	for ; started < n; started++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() { firstErr = err })
+				cancel()
+			}
+		}(started)
+	}
+	wg.Wait()
+	if firstErr == nil && started < n {
+		// canceled by the parent ctx before all chunks started
+		return ctx.Err()
+	}
+	return firstErr
+}
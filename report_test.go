@@ -0,0 +1,125 @@
+package jpush_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+func TestMsgIDLossless(t *testing.T) {
+	for _, data := range []string{`{"msg_id":18100157948851113}`, `{"msg_id":"18100157948851113"}`} {
+		var stat jpush.ReportReceivedResponse
+		if err := json.Unmarshal([]byte(data), &stat); err != nil {
+			t.Fatal(err)
+		}
+		if stat.MsgID != "18100157948851113" {
+			t.Errorf("%s: got msg_id %s", data, stat.MsgID)
+		}
+	}
+}
+
+func TestReportChunksInOrder(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	var ids []string
+	for i := 0; i < 2*jpush.MaxReportMsgIDs+50; i++ {
+		ids = append(ids, strconv.Itoa(18100157948851000+i))
+	}
+	srv.SetMessages(ids[220], jpush.ReportMessagesResponse{Android: &jpush.ReportAndroidMessage{Received: 7}})
+
+	messages, err := srv.JPushClient().ReportMessages(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, err := srv.JPushClient().ReportReceived(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != len(ids) || len(received) != len(ids) {
+		t.Fatalf("got %d messages and %d received, want %d", len(messages), len(received), len(ids))
+	}
+	for i, id := range ids {
+		if string(messages[i].MsgID) != id || string(received[i].MsgID) != id {
+			t.Fatalf("item %d: got %s and %s, want %s", i, messages[i].MsgID, received[i].MsgID, id)
+		}
+	}
+	if messages[220].Android == nil || messages[220].Android.Received != 7 {
+		t.Errorf("item 220: got %+v", messages[220].Android)
+	}
+}
+
+func TestReportUsersRoundTrip(t *testing.T) {
+	for _, data := range []string{
+		`{"time_unit":"HOUR","start":"2024-01-02 13","duration":2,"items":[{"time":"2024-01-02 13","android":{"new":1}},{"time":"2024-01-02 14"}]}`,
+		`{"time_unit":"DAY","start":"2024-01-02","duration":1,"items":[{"time":"2024-01-02","ios":{"active":3}}]}`,
+		`{"time_unit":"MONTH","start":"2024-01","duration":1,"items":[{"time":"2024-01"}]}`,
+	} {
+		var users jpush.ReportUsersResponse
+		if err := json.Unmarshal([]byte(data), &users); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := json.Marshal(&users)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != data {
+			t.Errorf("round trip:\ngot  %s\nwant %s", buf, data)
+		}
+	}
+}
+
+func TestReportUsersRangeStart(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	transport := &countTransport{path: "/v3/report/users"}
+	client := srv.JPushClient(jpush.WithTransport(transport))
+	// 2024-01-02 13:30 in Beijing time
+	from := time.Date(2024, 1, 2, 5, 30, 0, 0, time.UTC)
+
+	// the hour stats of one call are only of the day of start
+	day, err := client.ReportUsers(jpush.TimeUnitHour, from, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(day.Items) != 11 {
+		t.Errorf("got %d items of one call, want 11 hours to the midnight", len(day.Items))
+	}
+
+	atomic.StoreInt32(&transport.count, 0)
+	users, err := client.ReportUsersRange(jpush.TimeUnitHour, from, from.Add(30*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&transport.count); n != 2 {
+		t.Errorf("got %d calls, want split at the midnight into 2", n)
+	}
+	if users.Duration != 31 || len(users.Items) != 31 {
+		t.Fatalf("got duration %d and %d items, want 31", users.Duration, len(users.Items))
+	}
+	for i, item := range users.Items {
+		if want := from.Truncate(time.Hour).Add(time.Duration(i) * time.Hour); !time.Time(*item.Time).Equal(want) {
+			t.Errorf("item %d: got time %v, want %v", i, time.Time(*item.Time), want)
+		}
+	}
+	buf, err := json.Marshal(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Start string `json:"start"`
+		Items []struct {
+			Time string `json:"time"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw.Start != "2024-01-02 13" || raw.Items[30].Time != "2024-01-03 19" {
+		t.Errorf("got start %s and last item %s", raw.Start, raw.Items[30].Time)
+	}
+}
@@ -0,0 +1,128 @@
+package jpush
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy define retry policy of api request
+//
+// Only idempotent methods (GET, PUT, DELETE) are retried by default, a POST
+// request is retried only when its context is marked by RetryContext.
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数，包含第一次请求
+	MinBackoff  time.Duration // 第一次重试前的等待时间
+	MaxBackoff  time.Duration // 重试等待时间上限
+	Jitter      float64       // 等待时间随机抖动比例，取值 0~1
+	MaxWait     time.Duration // 频率超限时等待窗口重置的时间上限，0 不限制
+}
+
+// DefaultRetryPolicy default retry policy, 3 attempts from 500ms backoff
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Jitter:      0.2,
+		MaxWait:     time.Minute,
+	}
+}
+
+// backoff get the wait duration before the next attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delta := float64(wait) * p.Jitter
+		wait += time.Duration(delta * (2*rand.Float64() - 1))
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+type retryContextKey struct{}
+
+// RetryContext mark the request of ctx safe to retry, for example a push
+// request with cid which JPush will deduplicate
+func RetryContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+// retryIfCid mark ctx by RetryContext if cid not empty, the server
+// deduplicate the request by cid so it is safe to retry
+func retryIfCid(ctx context.Context, cid string) context.Context {
+	if cid == "" {
+		return ctx
+	}
+	return RetryContext(ctx)
+}
+
+// isRetryContext check if ctx marked by RetryContext
+func isRetryContext(ctx context.Context) bool {
+	retry, _ := ctx.Value(retryContextKey{}).(bool)
+	return retry
+}
+
+type noRetryContextKey struct{}
+
+// withoutRetry disable the client retry of the request of ctx, used when
+// the caller retries the request itself
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryContextKey{}, true)
+}
+
+// isRetryDisabled check if ctx marked by withoutRetry
+func isRetryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryContextKey{}).(bool)
+	return disabled
+}
+
+// isIdempotent check the http method is idempotent
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// retryAfter get the wait duration from rate limit headers when the quota is exhausted
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !IsRateLimited(err) || apiErr.Header == nil {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(apiErr.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if apiErr.RateLimit.Remaining == 0 && apiErr.RateLimit.Reset > 0 {
+		return time.Duration(apiErr.RateLimit.Reset) * time.Second
+	}
+	return 0
+}
+
+// sleep wait d or until ctx done, return false if ctx done
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,77 @@
+package jpush_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+func TestRetryWaitRateLimitReset(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetRateLimit(100, 0, time.Second)
+	client := srv.JPushClient(jpush.WithRetryPolicy(&jpush.RetryPolicy{
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+		MaxWait:     5 * time.Second,
+	}))
+
+	start := time.Now()
+	if _, err := client.ReportReceived([]string{"100"}); err != nil {
+		t.Fatalf("report after the window reset: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("retried after %v, want wait for X-Rate-Limit-Reset", elapsed)
+	}
+}
+
+func TestRetryMaxWait(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetRateLimit(100, 0, time.Minute)
+	client := srv.JPushClient(jpush.WithRetryPolicy(&jpush.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxWait:     100 * time.Millisecond,
+	}))
+
+	start := time.Now()
+	_, err := client.ReportReceived([]string{"100"})
+	if !jpush.IsRateLimited(err) {
+		t.Fatalf("got %v, want rate limited", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want no wait over MaxWait", elapsed)
+	}
+}
+
+func TestRetryPostOnlyWithCid(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.InjectError(jpushtest.EndpointPush, jpushtest.Fault{Status: 500, Code: jpush.CodeInternalError, Message: "internal error"})
+	transport := &countTransport{path: "/v3/push"}
+	client := srv.JPushClient(jpush.WithTransport(transport), jpush.WithRetryPolicy(&jpush.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+	}))
+	req := newPush(t)
+
+	if _, err := client.Push(req); err == nil {
+		t.Fatal("want push error")
+	}
+	if n := atomic.LoadInt32(&transport.count); n != 1 {
+		t.Errorf("push without cid: got %d calls, want 1", n)
+	}
+
+	atomic.StoreInt32(&transport.count, 0)
+	req.Cid = "appkey-1"
+	if _, err := client.Push(req); err == nil {
+		t.Fatal("want push error")
+	}
+	if n := atomic.LoadInt32(&transport.count); n != 3 {
+		t.Errorf("push with cid: got %d calls, want 3", n)
+	}
+}
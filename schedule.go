@@ -2,6 +2,7 @@ package jpush
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strconv"
 	"time"
@@ -12,7 +13,7 @@ type ScheduleTime time.Time
 
 // UnmarshalJSON unmarshal json
 func (s *ScheduleTime) UnmarshalJSON(data []byte) error {
-	t, err := time.Parse("2006-01-02 15:04:05", string(data))
+	t, err := time.Parse(`"2006-01-02 15:04:05"`, string(data))
 	if err != nil {
 		return err
 	}
@@ -22,7 +23,7 @@ func (s *ScheduleTime) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON marshal json
 func (s *ScheduleTime) MarshalJSON() (data []byte, err error) {
-	t := time.Time(*s).Format("2006-01-02 15:04:05")
+	t := time.Time(*s).Format(`"2006-01-02 15:04:05"`)
 	return []byte(t), nil
 }
 
@@ -77,12 +78,18 @@ type ScheduleMsgsResponse struct {
 // Schedule create schedule
 // POST /v3/schedules
 func (j *JPush) Schedule(req *ScheduleRequest) (*ScheduleResponse, error) {
+	return j.ScheduleContext(context.Background(), req)
+}
+
+// ScheduleContext like Schedule, with ctx for cancellation and deadline
+func (j *JPush) ScheduleContext(ctx context.Context, req *ScheduleRequest) (*ScheduleResponse, error) {
+	ctx = retryIfCid(ctx, req.Cid)
 	url := j.GetURL("schedule")
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("POST", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "POST", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -97,11 +104,16 @@ func (j *JPush) Schedule(req *ScheduleRequest) (*ScheduleResponse, error) {
 // SchedulePage get schedule list
 // GET /v3/schedules?page=
 func (j *JPush) SchedulePage(page int) (*SchedulePageResponse, error) {
+	return j.SchedulePageContext(context.Background(), page)
+}
+
+// SchedulePageContext like SchedulePage, with ctx for cancellation and deadline
+func (j *JPush) SchedulePageContext(ctx context.Context, page int) (*SchedulePageResponse, error) {
 	url := j.GetURL("schedule")
 	params := make(map[string]string)
 	params["page"] = strconv.Itoa(page)
 
-	resp, err := j.request("GET", url, nil, params)
+	resp, err := j.request(ctx, "GET", url, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -116,9 +128,14 @@ func (j *JPush) SchedulePage(page int) (*SchedulePageResponse, error) {
 // ScheduleID get schedule by id
 // GET /v3/schedules/{schedule_id}
 func (j *JPush) ScheduleID(scheduleID string) (*ScheduleResponse, error) {
+	return j.ScheduleIDContext(context.Background(), scheduleID)
+}
+
+// ScheduleIDContext like ScheduleID, with ctx for cancellation and deadline
+func (j *JPush) ScheduleIDContext(ctx context.Context, scheduleID string) (*ScheduleResponse, error) {
 	url := j.GetURL("schedule") + scheduleID
 
-	resp, err := j.request("GET", url, nil, nil)
+	resp, err := j.request(ctx, "GET", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -133,9 +150,14 @@ func (j *JPush) ScheduleID(scheduleID string) (*ScheduleResponse, error) {
 // ScheduleIDMsgs get all msg ids by schedule id
 // GET /v3/schedules/{schedule_id}/msg_ids
 func (j *JPush) ScheduleIDMsgs(scheduleID string) (*ScheduleMsgsResponse, error) {
+	return j.ScheduleIDMsgsContext(context.Background(), scheduleID)
+}
+
+// ScheduleIDMsgsContext like ScheduleIDMsgs, with ctx for cancellation and deadline
+func (j *JPush) ScheduleIDMsgsContext(ctx context.Context, scheduleID string) (*ScheduleMsgsResponse, error) {
 	url := j.GetURL("schedule") + scheduleID + "/msg_ids"
 
-	resp, err := j.request("GET", url, nil, nil)
+	resp, err := j.request(ctx, "GET", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -150,12 +172,18 @@ func (j *JPush) ScheduleIDMsgs(scheduleID string) (*ScheduleMsgsResponse, error)
 // SchedulePut modify schedule
 // PUT /v3/schedules/{schedule_id}
 func (j *JPush) SchedulePut(scheduleID string, req *ScheduleRequest) (*ScheduleResponse, error) {
+	return j.SchedulePutContext(context.Background(), scheduleID, req)
+}
+
+// SchedulePutContext like SchedulePut, with ctx for cancellation and deadline
+func (j *JPush) SchedulePutContext(ctx context.Context, scheduleID string, req *ScheduleRequest) (*ScheduleResponse, error) {
+	ctx = retryIfCid(ctx, req.Cid)
 	url := j.GetURL("schedule") + scheduleID
 	buf, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := j.request("PUT", url, bytes.NewReader(buf), nil)
+	resp, err := j.request(ctx, "PUT", url, bytes.NewReader(buf), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -170,9 +198,14 @@ func (j *JPush) SchedulePut(scheduleID string, req *ScheduleRequest) (*ScheduleR
 // ScheduleDelete delete schedule
 // DELETE /v3/schedules/{schedule_id}
 func (j *JPush) ScheduleDelete(scheduleID string) (*DefaultResponse, error) {
+	return j.ScheduleDeleteContext(context.Background(), scheduleID)
+}
+
+// ScheduleDeleteContext like ScheduleDelete, with ctx for cancellation and deadline
+func (j *JPush) ScheduleDeleteContext(ctx context.Context, scheduleID string) (*DefaultResponse, error) {
 	url := j.GetURL("schedule") + scheduleID
 
-	resp, err := j.request("DELETE", url, nil, nil)
+	resp, err := j.request(ctx, "DELETE", url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,296 @@
+package jpush
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// final reasons of TrackEvent
+const (
+	TrackStable   = "stable"   // 统计数据连续多次不变
+	TrackExpired  = "expired"  // 超过离线保留时长
+	TrackRejected = "rejected" // msg_id 被服务端拒绝，如 msg_id 无效
+)
+
+// TrackStat delivery stat of one platform
+type TrackStat struct {
+	Target   int
+	Received int
+	Clicked  int
+}
+
+// TrackEvent progress of a tracked push, the last event of each msg id has
+// Final set with the summary
+type TrackEvent struct {
+	MsgID   string
+	Android TrackStat
+	IOS     TrackStat
+	Polls   int       // 已查询次数
+	Final   bool      // 是否为最终结果
+	Reason  string    // 最终结果的原因，TrackStable、TrackExpired 或 TrackRejected
+	Err     error     // 查询失败的错误
+	Time    time.Time // 查询时间
+}
+
+// trackedPush state of a tracked push
+type trackedPush struct {
+	msgID    string
+	deadline time.Time
+	next     time.Time
+	interval time.Duration
+	stable   int
+	polls    int
+	android  TrackStat
+	ios      TrackStat
+}
+
+// Tracker poll the messages report of registered msg ids until the counts
+// stop changing or the time to live expires, the interval grows from
+// MinInterval to MaxInterval while the counts are unchanged
+type Tracker struct {
+	client      *JPush
+	MinInterval time.Duration // 最短查询间隔，默认 10 秒
+	MaxInterval time.Duration // 最长查询间隔，默认 5 分钟
+	StablePolls int           // 连续不变多少次视为最终结果，默认 3
+	TTL         time.Duration // Track 未指定时的默认跟踪时长，默认 1 天
+
+	mu     sync.Mutex
+	pushes map[string]*trackedPush
+	wake   chan struct{}
+	events chan TrackEvent
+}
+
+// NewTracker new tracker of client
+func NewTracker(client *JPush) *Tracker {
+	return &Tracker{
+		client:      client,
+		MinInterval: 10 * time.Second,
+		MaxInterval: 5 * time.Minute,
+		StablePolls: 3,
+		TTL:         24 * time.Hour,
+		pushes:      make(map[string]*trackedPush),
+		wake:        make(chan struct{}, 1),
+		events:      make(chan TrackEvent, 64),
+	}
+}
+
+// Events get the channel of progress events, it is closed when Run returns
+func (t *Tracker) Events() <-chan TrackEvent {
+	return t.events
+}
+
+// Track register msgID to track for ttl, such as the time_to_live of the
+// push, ttl 0 use the TTL of tracker
+func (t *Tracker) Track(msgID string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = t.TTL
+	}
+	now := time.Now()
+	t.mu.Lock()
+	t.pushes[msgID] = &trackedPush{
+		msgID:    msgID,
+		deadline: now.Add(ttl),
+		next:     now.Add(t.MinInterval),
+		interval: t.MinInterval,
+	}
+	t.mu.Unlock()
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pending get the number of msg ids not final
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pushes)
+}
+
+// Run poll the reports until ctx is done, it must be called only once
+func (t *Tracker) Run(ctx context.Context) error {
+	defer close(t.events)
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		due, wait := t.due(time.Now())
+		if len(due) > 0 {
+			if err := t.poll(ctx, due); err != nil {
+				return err
+			}
+			continue
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// due get the pushes due to poll and the wait until the next one
+func (t *Tracker) due(now time.Time) ([]*trackedPush, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var due []*trackedPush
+	wait := time.Hour
+	for _, p := range t.pushes {
+		if !p.next.After(now) {
+			due = append(due, p)
+		} else if d := p.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return due, wait
+}
+
+// poll query the messages report of due pushes and emit the events
+func (t *Tracker) poll(ctx context.Context, due []*trackedPush) error {
+	if err := t.client.waitQuota(ctx); err != nil {
+		return err
+	}
+	ids := make([]string, len(due))
+	for i, p := range due {
+		ids[i] = p.msgID
+	}
+	now := time.Now()
+	stats, err := t.client.ReportMessagesContext(ctx, ids)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(due) > 1 && isRejected(err) {
+			// one bad msg id fails the whole call, poll the halves to isolate it
+			half := len(due) / 2
+			if err := t.poll(ctx, due[:half]); err != nil {
+				return err
+			}
+			return t.poll(ctx, due[half:])
+		}
+		for _, p := range due {
+			if !t.emit(ctx, t.fail(p, err, now)) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+	byID := make(map[string]*ReportMessagesResponse, len(stats))
+	for i := range stats {
+		byID[string(stats[i].MsgID)] = &stats[i]
+	}
+	for _, p := range due {
+		event, changed := t.update(p, byID[p.msgID], now)
+		if changed || event.Final {
+			if !t.emit(ctx, event) {
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// update update the push with stat, return the event and if counts changed
+func (t *Tracker) update(p *trackedPush, stat *ReportMessagesResponse, now time.Time) (TrackEvent, bool) {
+	var android, ios TrackStat
+	if stat != nil && stat.Android != nil {
+		android = TrackStat{Target: stat.Android.Target, Received: stat.Android.Received, Clicked: stat.Android.Click}
+	}
+	if stat != nil && stat.IOS != nil {
+		ios = TrackStat{Target: stat.IOS.ApnsTarget, Received: stat.IOS.ApnsReceived, Clicked: stat.IOS.Click}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p.polls++
+	changed := android != p.android || ios != p.ios
+	p.android, p.ios = android, ios
+	if changed {
+		p.stable = 0
+		p.interval = t.MinInterval
+	} else {
+		p.stable++
+		p.interval *= 2
+		if p.interval > t.MaxInterval {
+			p.interval = t.MaxInterval
+		}
+	}
+	p.next = now.Add(p.interval)
+	event := p.event(now)
+	// all zero counts are not stable, the report may not be ready yet
+	empty := android == TrackStat{} && ios == TrackStat{}
+	switch {
+	case !empty && p.stable >= t.StablePolls:
+		event.Final, event.Reason = true, TrackStable
+	case !now.Before(p.deadline):
+		event.Final, event.Reason = true, TrackExpired
+	}
+	if event.Final {
+		delete(t.pushes, p.msgID)
+	}
+	return event, changed
+}
+
+// fail update the push failed to poll with err, the push rejected by the
+// server or expired is final
+func (t *Tracker) fail(p *trackedPush, err error, now time.Time) TrackEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p.polls++
+	p.interval *= 2
+	if p.interval > t.MaxInterval {
+		p.interval = t.MaxInterval
+	}
+	p.next = now.Add(p.interval)
+	if wait := retryAfter(err); wait > p.interval {
+		p.next = now.Add(wait)
+	}
+	event := p.event(now)
+	event.Err = err
+	switch {
+	case isRejected(err):
+		event.Final, event.Reason = true, TrackRejected
+	case !now.Before(p.deadline):
+		event.Final, event.Reason = true, TrackExpired
+	}
+	if event.Final {
+		delete(t.pushes, p.msgID)
+	}
+	return event
+}
+
+// isRejected check if the report request is rejected for invalid params
+func isRejected(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest
+}
+
+// event get the event of current state
+func (p *trackedPush) event(now time.Time) TrackEvent {
+	return TrackEvent{
+		MsgID:   p.msgID,
+		Android: p.android,
+		IOS:     p.ios,
+		Polls:   p.polls,
+		Time:    now,
+	}
+}
+
+// emit send event to the events channel
+func (t *Tracker) emit(ctx context.Context, event TrackEvent) bool {
+	select {
+	case t.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+package jpush_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deaswang/jpush-api-golang"
+	"github.com/deaswang/jpush-api-golang/jpushtest"
+)
+
+// runTracker run tracker until all pushes are final, return the final events
+func runTracker(t *testing.T, tracker *jpush.Tracker, n int) map[string]jpush.TrackEvent {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- tracker.Run(ctx) }()
+	finals := make(map[string]jpush.TrackEvent)
+	for event := range tracker.Events() {
+		if event.Final {
+			finals[event.MsgID] = event
+			if len(finals) == n {
+				cancel()
+			}
+		}
+	}
+	<-done
+	if len(finals) != n {
+		t.Fatalf("got %d final events, want %d", len(finals), n)
+	}
+	if pending := tracker.Pending(); pending != 0 {
+		t.Fatalf("pending %d, want 0", pending)
+	}
+	return finals
+}
+
+func newTracker(srv *jpushtest.Server) *jpush.Tracker {
+	client := srv.JPushClient(jpush.WithRetryPolicy(&jpush.RetryPolicy{MaxAttempts: 1}))
+	tracker := jpush.NewTracker(client)
+	tracker.MinInterval = 5 * time.Millisecond
+	tracker.MaxInterval = 20 * time.Millisecond
+	return tracker
+}
+
+func TestTrackerIsolateRejectedMsgID(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.SetMessages("100", jpush.ReportMessagesResponse{Android: &jpush.ReportAndroidMessage{Target: 2, Received: 2}})
+
+	tracker := newTracker(srv)
+	tracker.Track("100", time.Minute)
+	tracker.Track("bad-id", time.Minute)
+	finals := runTracker(t, tracker, 2)
+
+	if e := finals["100"]; e.Reason != jpush.TrackStable || e.Android.Received != 2 {
+		t.Errorf("msg 100 final %+v, want stable with 2 received", e)
+	}
+	if e := finals["bad-id"]; e.Reason != jpush.TrackRejected || e.Err == nil {
+		t.Errorf("bad-id final %+v, want rejected", e)
+	}
+}
+
+func TestTrackerExpireOnError(t *testing.T) {
+	srv := jpushtest.NewServer("appkey", "secret")
+	defer srv.Close()
+	srv.InjectError(jpushtest.EndpointReport, jpushtest.Fault{Status: 500, Code: jpush.CodeInternalError, Message: "internal error"})
+
+	tracker := newTracker(srv)
+	tracker.Track("100", 50*time.Millisecond)
+	finals := runTracker(t, tracker, 1)
+
+	e := finals["100"]
+	if e.Reason != jpush.TrackExpired || e.Err == nil {
+		t.Errorf("final %+v, want expired with error", e)
+	}
+	if e.Polls > 10 {
+		t.Errorf("polled %d times, want backoff on error", e.Polls)
+	}
+}
@@ -0,0 +1,336 @@
+package jpush
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// push request limits
+const (
+	MaxAndroidPayload  = 4000   // Android notification + message 最大字节数
+	MaxIOSPayload      = 2048   // iOS APNs 通知最大字节数
+	MaxTags            = 20     // tag、tag_and、tag_not 每次推送最多个数
+	MaxRegistrationIDs = 1000   // registration_id、alias 每次推送最多个数
+	MaxSegments        = 1      // segment、abtest 每次推送最多个数
+	MaxTagLength       = 40     // 每个 tag、alias 最大字节数
+	MaxTimeToLive      = 864000 // 离线消息最长保留秒数，10 天
+	MaxBigPushDuration = 1400   // 定速推送最长分钟数
+	MaxBadge           = 99     // iOS badge 及 +N/-N 增减值的最大值
+)
+
+// Violation a limit violated by the push request
+type Violation struct {
+	Field   string // 违反限制的字段，如 audience.tag、notification.ios
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// ValidationError all violations of the push request
+type ValidationError []Violation
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.String()
+	}
+	return "jpush: invalid push request: " + strings.Join(msgs, "; ")
+}
+
+// Validate check the push request locally before sending, return
+// ValidationError with all violations of the payload size, audience
+// cardinality and option range limits
+func (r *PushRequest) Validate() error {
+	var errs ValidationError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if r.Platform == nil {
+		add("platform", "required")
+	}
+	if r.Audience == nil {
+		add("audience", "required")
+	} else if !r.Audience.isAll {
+		if r.Audience.Aud == nil {
+			add("audience", "required")
+		} else {
+			checkAudience(r.Audience.Aud, add)
+		}
+	}
+	if r.LiveActivity != nil {
+		r.checkLiveActivity(add)
+	} else if r.Notification == nil && r.Message == nil {
+		add("notification", "notification or message is required")
+	} else if r.Audience != nil && r.Audience.Aud != nil && r.Audience.Aud.LiveActivityID != "" {
+		add("audience.live_activity_id", "only for live activity")
+	}
+	if r.Notification != nil && r.Notification.Android != nil {
+		checkAndroid(r.Notification.Android, add)
+	}
+	if r.Notification != nil && r.Notification.IOS != nil {
+		checkIOS(r.Notification.IOS, add)
+	}
+	if r.Notification3rd != nil {
+		if r.Message == nil {
+			add("notification_3rd", "requires message")
+		}
+		if r.Notification3rd.Content == "" {
+			add("notification_3rd.content", "required")
+		}
+	}
+	if r.InAppMessage != nil && r.InAppMessage.InAppMessage && r.Notification == nil {
+		add("inapp_message", "requires notification")
+	}
+	if n := r.Notification; n != nil && n.HMOS != nil && n.HMOS.Category == "" {
+		add("notification.hmos.category", "required")
+	}
+	if n := r.Notification; n != nil && n.QuickApp != nil {
+		if n.QuickApp.Title == "" {
+			add("notification.quickapp.title", "required")
+		}
+		if n.QuickApp.Page == "" {
+			add("notification.quickapp.page", "required")
+		}
+	}
+	if r.Platform == nil || r.Platform.has(PlatformAndroid) {
+		if size, err := r.androidPayloadSize(); err != nil {
+			add("notification.android", "payload not encodable: %v", err)
+		} else if size > MaxAndroidPayload {
+			add("notification.android", "payload %d bytes exceed the limit %d", size, MaxAndroidPayload)
+		}
+	}
+	if r.Platform == nil || r.Platform.has(PlatformIOS) {
+		if size, err := r.iosPayloadSize(); err != nil {
+			add("notification.ios", "payload not encodable: %v", err)
+		} else if size > MaxIOSPayload {
+			add("notification.ios", "payload %d bytes exceed the limit %d", size, MaxIOSPayload)
+		}
+	}
+	if o := r.Options; o != nil {
+		if o.TimeToLive < 0 || o.TimeToLive > MaxTimeToLive {
+			add("options.time_to_live", "%d out of range 0~%d", o.TimeToLive, MaxTimeToLive)
+		}
+		if o.BigPushDuration < 0 || o.BigPushDuration > MaxBigPushDuration {
+			add("options.big_push_duration", "%d out of range 0~%d", o.BigPushDuration, MaxBigPushDuration)
+		}
+		if o.Classification != 0 && o.Classification != 1 {
+			add("options.classification", "must be 0 or 1")
+		}
+		if o.ThirdPartyChannel != nil {
+			for field, distribution := range o.ThirdPartyChannel.distributions() {
+				if !distribution.valid() {
+					add("options.third_party_channel."+field, "unknown distribution %q", distribution)
+				}
+			}
+		}
+	}
+	if r.Callback != nil {
+		checkCallback("callback", r.Callback, add)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkAudience check the audience cardinality and value length
+func checkAudience(a *Audience, add func(field, format string, args ...interface{})) {
+	limits := []struct {
+		field  string
+		values []string
+		max    int
+	}{
+		{"audience.tag", a.Tag, MaxTags},
+		{"audience.tag_and", a.TagAnd, MaxTags},
+		{"audience.tag_not", a.TagNot, MaxTags},
+		{"audience.alias", a.Alias, MaxRegistrationIDs},
+		{"audience.registration_id", a.RegistrationID, MaxRegistrationIDs},
+		{"audience.segment", a.Segment, MaxSegments},
+		{"audience.abtest", a.ABTest, MaxSegments},
+	}
+	empty := true
+	for _, l := range limits {
+		if len(l.values) > 0 {
+			empty = false
+		}
+		if len(l.values) > l.max {
+			add(l.field, "%d values exceed the limit %d", len(l.values), l.max)
+		}
+	}
+	if empty && a.File == nil && a.LiveActivityID == "" {
+		add("audience", "no target")
+	}
+	for _, l := range limits[:4] {
+		for _, value := range l.values {
+			if len(value) > MaxTagLength {
+				add(l.field, "%q is longer than %d bytes", value, MaxTagLength)
+			}
+		}
+	}
+}
+
+// checkAndroid check the android notification style and ranges
+func checkAndroid(n *NotificationAndroid, add func(field, format string, args ...interface{})) {
+	switch n.Style {
+	case StyleDefault:
+	case StyleBigText:
+		if n.BigText == "" {
+			add("notification.android.big_text", "required by big text style")
+		}
+	case StyleInbox:
+		if len(n.Inbox) == 0 {
+			add("notification.android.inbox", "required by inbox style")
+		}
+	case StyleBigPicture:
+		if n.BigPicPath == "" {
+			add("notification.android.big_pic_path", "required by big picture style")
+		}
+	default:
+		add("notification.android.style", "unknown style %d", n.Style)
+	}
+	if n.Priority < PriorityMin || n.Priority > PriorityMax {
+		add("notification.android.priority", "%d out of range %d~%d", n.Priority, PriorityMin, PriorityMax)
+	}
+	if n.AlertType < AlertTypeAll || n.AlertType > AlertTypeSound|AlertTypeVibrate|AlertTypeLights {
+		add("notification.android.alert_type", "unknown alert type %d", n.AlertType)
+	}
+	if n.DisplayForeground != "" && n.DisplayForeground != "0" && n.DisplayForeground != "1" {
+		add("notification.android.display_foreground", "must be \"0\" or \"1\"")
+	}
+}
+
+// checkIOS check the ios notification badge, sound and ranges
+func checkIOS(n *NotificationIOS, add func(field, format string, args ...interface{})) {
+	if n.Badge != "" {
+		if _, num, ok := n.Badge.parse(); !ok {
+			add("notification.ios.badge", "%q is not a number or +N/-N", n.Badge)
+		} else if num > MaxBadge {
+			add("notification.ios.badge", "%q out of range 0~%d", n.Badge, MaxBadge)
+		}
+	}
+	switch sound := n.Sound.(type) {
+	case nil, string:
+	case *IOSSound:
+		if sound.Volume < 0 || sound.Volume > 1 {
+			add("notification.ios.sound.volume", "%v out of range 0~1", sound.Volume)
+		}
+	case IOSSound, map[string]interface{}:
+	default:
+		add("notification.ios.sound", "unsupported type %T", sound)
+	}
+	switch n.InterruptionLevel {
+	case "", InterruptionPassive, InterruptionActive, InterruptionTimeSensitive, InterruptionCritical:
+	default:
+		add("notification.ios.interruption-level", "unknown level %q", n.InterruptionLevel)
+	}
+	if n.RelevanceScore < 0 || n.RelevanceScore > 1 {
+		add("notification.ios.relevance-score", "%v out of range 0~1", n.RelevanceScore)
+	}
+}
+
+// has check if the platform is targeted
+func (p *Platform) has(platform string) bool {
+	if p.isAll {
+		return true
+	}
+	for _, name := range p.Platforms {
+		if name == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSize get the json size of v without html escape, as the server counts
+func jsonSize(v interface{}) (int, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return 0, err
+	}
+	return len(bytes.TrimSpace(buf.Bytes())), nil
+}
+
+// androidPayloadSize get the size of android notification and message
+func (r *PushRequest) androidPayloadSize() (int, error) {
+	var parts []interface{}
+	if n := r.Notification; n != nil {
+		if n.Android != nil {
+			parts = append(parts, n.Android)
+		} else if n.Alert != "" {
+			parts = append(parts, map[string]string{"alert": n.Alert})
+		}
+	}
+	if r.Message != nil {
+		parts = append(parts, r.Message)
+	}
+	size := 0
+	for _, part := range parts {
+		n, err := jsonSize(part)
+		if err != nil {
+			return 0, err
+		}
+		size += n
+	}
+	return size, nil
+}
+
+// iosPayloadSize get the size of the apns payload forwarded to APNs
+func (r *PushRequest) iosPayloadSize() (int, error) {
+	n := r.Notification
+	if n == nil {
+		return 0, nil
+	}
+	aps := make(map[string]interface{})
+	payload := map[string]interface{}{"aps": aps}
+	if n.IOS == nil {
+		if n.Alert == "" {
+			return 0, nil
+		}
+		aps["alert"] = n.Alert
+		return jsonSize(payload)
+	}
+	ios := n.IOS
+	if ios.Alert != nil {
+		aps["alert"] = ios.Alert
+	} else if n.Alert != "" {
+		aps["alert"] = n.Alert
+	}
+	if ios.Sound != nil && ios.Sound != "" {
+		aps["sound"] = ios.Sound
+	}
+	if ios.Badge != "" {
+		aps["badge"] = ios.Badge
+	}
+	if ios.ContentAvailable {
+		aps["content-available"] = 1
+	}
+	if ios.MutableContent {
+		aps["mutable-content"] = 1
+	}
+	if ios.Category != "" {
+		aps["category"] = ios.Category
+	}
+	if ios.ThreadID != "" {
+		aps["thread-id"] = ios.ThreadID
+	}
+	if ios.InterruptionLevel != "" {
+		aps["interruption-level"] = ios.InterruptionLevel
+	}
+	if ios.RelevanceScore != 0 {
+		aps["relevance-score"] = ios.RelevanceScore
+	}
+	if ios.TargetContentID != "" {
+		aps["target-content-id"] = ios.TargetContentID
+	}
+	for key, value := range ios.Extras {
+		payload[key] = value
+	}
+	return jsonSize(payload)
+}
@@ -0,0 +1,92 @@
+package jpush_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/deaswang/jpush-api-golang"
+)
+
+func TestValidateDistributionFcm(t *testing.T) {
+	for _, d := range []jpush.DistributionFcm{jpush.DistributionFcmJPush, jpush.DistributionFcmFcm, jpush.DistributionFcmPns} {
+		_, err := jpush.NewPush().Android().ToAll().Alert("hello").
+			ThirdPartyChannel(&jpush.ThirdPartyChannel{Xiaomi: &jpush.ThirdPartyXiaomi{
+				Distribution:    jpush.DistributionSecondaryPush,
+				DistributionFcm: d,
+			}}).
+			Build()
+		if err != nil {
+			t.Errorf("distribution_fcm %q: %v", d, err)
+		}
+	}
+
+	_, err := jpush.NewPush().Android().ToAll().Alert("hello").
+		ThirdPartyChannel(&jpush.ThirdPartyChannel{Huawei: &jpush.ThirdPartyHuawei{DistributionFcm: "ospush"}}).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), "huawei.distribution_fcm") {
+		t.Errorf("distribution_fcm ospush: got %v, want violation", err)
+	}
+}
+
+func TestValidateLiveActivityAudience(t *testing.T) {
+	update := &jpush.LiveActivityIOS{Event: jpush.LiveActivityUpdate, ContentState: map[string]interface{}{"eta": 10}}
+	if _, err := jpush.NewPush().IOS().ToLiveActivity("activity").LiveActivity(update).Build(); err != nil {
+		t.Errorf("live activity id: %v", err)
+	}
+	for name, b := range map[string]*jpush.PushBuilder{
+		"all":  jpush.NewPush().IOS().ToAll().LiveActivity(update),
+		"tags": jpush.NewPush().IOS().ToTags("vip").LiveActivity(update),
+	} {
+		_, err := b.Build()
+		if err == nil || !strings.Contains(err.Error(), "audience.live_activity_id") {
+			t.Errorf("%s: got %v, want live_activity_id violation", name, err)
+		}
+	}
+}
+
+func TestValidateIOSBadge(t *testing.T) {
+	for badge, want := range map[jpush.IOSBadge]string{
+		"5":   `5`,
+		"05":  `5`,
+		"00":  `0`,
+		"99":  `99`,
+		"+05": `"+5"`,
+		"-1":  `"-1"`,
+	} {
+		req, err := jpush.NewPush().IOS().ToAll().Alert("x").IOSBadge(badge).Build()
+		if err != nil {
+			t.Errorf("badge %q: %v", badge, err)
+			continue
+		}
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Errorf("badge %q: marshal %v", badge, err)
+			continue
+		}
+		if got := string(data); !strings.Contains(got, `"badge":`+want) {
+			t.Errorf("badge %q: got %s, want badge %s", badge, got, want)
+		}
+	}
+
+	for _, badge := range []jpush.IOSBadge{"100", "+100", "x", "+-5", "-+5", "+", " 5", "5.0"} {
+		_, err := jpush.NewPush().IOS().ToAll().Alert("x").IOSBadge(badge).Build()
+		if err == nil || !strings.Contains(err.Error(), "notification.ios.badge") {
+			t.Errorf("badge %q: got %v, want badge violation", badge, err)
+		}
+	}
+}
+
+func TestValidateNotEncodable(t *testing.T) {
+	extras := map[string]interface{}{"ch": make(chan int)}
+	_, err := jpush.NewPush().Android().IOS().ToAll().Alert("x").
+		AndroidExtras(extras).IOSExtras(extras).Build()
+	if err == nil {
+		t.Fatal("want violation")
+	}
+	for _, field := range []string{"notification.android: payload not encodable", "notification.ios: payload not encodable"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("got %v, want %q", err, field)
+		}
+	}
+}